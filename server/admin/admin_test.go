@@ -0,0 +1,69 @@
+package admin_test
+
+import (
+	"flag"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/admin"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsole_Exec(t *testing.T) {
+	var ready = make(chan struct{})
+	srv := server.New(server.NetworkNone)
+	console := admin.NewConsole(srv, admin.WithHTTP("127.0.0.1:9992", "secret"))
+	console.Register(&admin.Command{
+		Name:  "echo",
+		Usage: "[-upper] <content>",
+		Flags: func(flags *flag.FlagSet) {
+			flags.Bool("upper", false, "convert to upper case")
+		},
+		Handle: func(ctx *admin.Context) error {
+			content := strings.Join(ctx.Args, " ")
+			if ctx.Flags.Lookup("upper").Value.String() == "true" {
+				content = strings.ToUpper(content)
+			}
+			ctx.Println(content)
+			return nil
+		},
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	defer srv.Shutdown()
+
+	var output strings.Builder
+	if err := console.Exec(`echo -upper "hello world"`, &output); err != nil || output.String() != "HELLO WORLD\n" {
+		t.Fatal(output.String(), err)
+	}
+	if err := console.Exec("none", io.Discard); err == nil {
+		t.Fatal("expect command not found")
+	}
+
+	for token, expect := range map[string]int{"secret": http.StatusOK, "wrong": http.StatusUnauthorized} {
+		request, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1:9992/admin", strings.NewReader("echo minotaur"))
+		request.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != expect || (expect == http.StatusOK && string(body) != "minotaur\n") {
+			t.Fatal(token, resp.StatusCode, string(body))
+		}
+	}
+}
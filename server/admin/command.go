@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"io"
+)
+
+// Handle 指令处理函数
+type Handle func(ctx *Context) error
+
+// Command 管理指令
+type Command struct {
+	Name        string                    // 指令名称
+	Aliases     []string                  // 指令别名
+	Usage       string                    // 参数用法，例如 "[-force] <id>"
+	Description string                    // 指令描述
+	Flags       func(flags *flag.FlagSet) // 标志定义函数，可为空
+	Handle      Handle                    // 指令处理函数
+}
+
+// Context 指令执行上下文
+type Context struct {
+	Server  *server.Server // 服务器
+	Command *Command       // 正在执行的指令
+	Flags   *flag.FlagSet  // 解析完成的标志
+	Args    []string       // 解析标志后剩余的参数
+	Output  io.Writer      // 执行结果输出
+}
+
+// Arg 获取特定位置的参数，不存在时返回空字符串
+func (slf *Context) Arg(i int) string {
+	if i < 0 || i >= len(slf.Args) {
+		return ""
+	}
+	return slf.Args[i]
+}
+
+// Printf 格式化输出执行结果
+func (slf *Context) Printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(slf.Output, format, args...)
+}
+
+// Println 输出一行执行结果
+func (slf *Context) Println(args ...any) {
+	_, _ = fmt.Fprintln(slf.Output, args...)
+}
+
+// flagSet 创建指令的标志集合
+func (slf *Command) flagSet(output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(slf.Name, flag.ContinueOnError)
+	flags.SetOutput(output)
+	if slf.Flags != nil {
+		slf.Flags(flags)
+	}
+	flags.Usage = func() {
+		slf.help(flags, output)
+	}
+	return flags
+}
+
+// help 输出指令的帮助信息
+func (slf *Command) help(flags *flag.FlagSet, output io.Writer) {
+	_, _ = fmt.Fprintf(output, "Usage: %s %s\n", slf.Name, slf.Usage)
+	if len(slf.Aliases) > 0 {
+		_, _ = fmt.Fprintf(output, "Aliases: %v\n", slf.Aliases)
+	}
+	if len(slf.Description) > 0 {
+		_, _ = fmt.Fprintf(output, "\n%s\n", slf.Description)
+	}
+	var hasFlags bool
+	flags.VisitAll(func(f *flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		_, _ = fmt.Fprintln(output, "\nFlags:")
+		flags.PrintDefaults()
+	}
+}
@@ -0,0 +1,222 @@
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewConsole 基于 server.Server 创建管理控制台
+//   - 将内置 help、shutdown 及 maintenance 指令，可通过注册同名指令进行覆盖
+//   - 各指令执行通道将在服务器启动完成后开始运行，并在服务器停止时关闭，应在服务器运行前创建
+func NewConsole(srv *server.Server, options ...Option) *Console {
+	console := &Console{
+		srv:      srv,
+		timeout:  DefaultTimeout,
+		commands: map[string]*Command{},
+	}
+	console.builtin()
+	for _, option := range options {
+		option(console)
+	}
+
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		console.running.Store(true)
+		for _, transport := range console.transports {
+			if err := transport.start(console); err != nil {
+				log.Error("Admin", log.String("transport", transport.String()), log.Err(err))
+			}
+		}
+	}, math.MaxInt)
+	srv.RegStopEvent(func(srv *server.Server) {
+		console.running.Store(false)
+		for _, transport := range console.transports {
+			transport.stop()
+		}
+	}, math.MinInt)
+	return console
+}
+
+// Console 管理控制台
+type Console struct {
+	srv        *server.Server      // 服务器
+	timeout    time.Duration       // 指令执行超时时间
+	commands   map[string]*Command // 指令 [名称或别名]
+	builtins   map[string]bool     // 尚未被覆盖的内置指令 [名称或别名]
+	transports []transport         // 指令执行通道
+	running    atomic.Bool         // 服务器是否正在运行
+	rw         sync.RWMutex        // 指令锁
+}
+
+// Register 注册管理指令
+//   - 指令名称或别名与已注册的指令重复时将发生 panic，内置指令除外
+func (slf *Console) Register(command *Command) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	names := append([]string{command.Name}, command.Aliases...)
+	for _, name := range names {
+		if _, exist := slf.commands[name]; exist && !slf.builtins[name] {
+			panic(fmt.Errorf("%w: %s", ErrCommandExists, name))
+		}
+	}
+	for _, name := range names {
+		if slf.builtins[name] {
+			slf.unregister(name)
+		}
+		slf.commands[name] = command
+	}
+}
+
+// unregister 移除内置指令的名称及全部别名
+func (slf *Console) unregister(name string) {
+	command := slf.commands[name]
+	for _, n := range append([]string{command.Name}, command.Aliases...) {
+		delete(slf.commands, n)
+		delete(slf.builtins, n)
+	}
+}
+
+// GetCommands 获取所有已注册的指令，按名称排序
+func (slf *Console) GetCommands() []*Command {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	var commands = make([]*Command, 0, len(slf.commands))
+	for name, command := range slf.commands {
+		if name == command.Name {
+			commands = append(commands, command)
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Exec 执行一行指令，执行结果将写入 output
+//   - 指令的处理函数将在服务器的消息循环中执行，该函数将阻塞直到执行完成或超时，因此不应在消息循环中调用
+//   - 空指令将被忽略
+func (slf *Console) Exec(line string, output io.Writer) error {
+	args, err := splitArgs(line)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+	slf.rw.RLock()
+	command, exist := slf.commands[args[0]]
+	slf.rw.RUnlock()
+	if !exist {
+		return fmt.Errorf("%w: %s", ErrCommandNotFound, args[0])
+	}
+
+	flags := command.flagSet(output)
+	if err = flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if !slf.running.Load() {
+		return ErrConsoleNotRunning
+	}
+
+	var buf bytes.Buffer
+	var done = make(chan error, 1)
+	var ctx = &Context{
+		Server:  slf.srv,
+		Command: command,
+		Flags:   flags,
+		Args:    flags.Args(),
+		Output:  &buf,
+	}
+	server.PushSystemMessage(slf.srv, func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- fmt.Errorf("%v", err)
+			}
+		}()
+		done <- command.Handle(ctx)
+	}, "AdminCommand", command.Name)
+
+	select {
+	case err = <-done:
+		_, _ = output.Write(buf.Bytes())
+		return err
+	case <-time.After(slf.timeout):
+		return fmt.Errorf("%w: %s", ErrCommandTimeout, command.Name)
+	}
+}
+
+// builtin 注册内置指令
+func (slf *Console) builtin() {
+	var commands = []*Command{
+		{
+			Name:        "help",
+			Usage:       "[command]",
+			Description: "Show the list of commands or the usage of a command.",
+			Handle:      slf.help,
+		},
+		{
+			Name:        "shutdown",
+			Aliases:     []string{"exit", "quit", "close"},
+			Description: "Shutdown the server.",
+			Handle: func(ctx *Context) error {
+				ctx.Println("shutting down")
+				go ctx.Server.Shutdown()
+				return nil
+			},
+		},
+		{
+			Name:        "maintenance",
+			Usage:       "<on|off>",
+			Description: "Enter or exit maintenance mode, new connections will be refused during maintenance.",
+			Handle: func(ctx *Context) error {
+				switch ctx.Arg(0) {
+				case "on":
+					ctx.Server.EnterMaintenance()
+				case "off":
+					ctx.Server.ExitMaintenance()
+				case "":
+				default:
+					return fmt.Errorf("invalid argument: %s", ctx.Arg(0))
+				}
+				ctx.Printf("maintenance: %v\n", ctx.Server.IsMaintenance())
+				return nil
+			},
+		},
+	}
+	slf.builtins = map[string]bool{}
+	for _, command := range commands {
+		slf.Register(command)
+		for _, name := range append([]string{command.Name}, command.Aliases...) {
+			slf.builtins[name] = true
+		}
+	}
+}
+
+// help 内置 help 指令的处理函数
+func (slf *Console) help(ctx *Context) error {
+	if name := ctx.Arg(0); len(name) > 0 {
+		slf.rw.RLock()
+		command, exist := slf.commands[name]
+		slf.rw.RUnlock()
+		if !exist {
+			return fmt.Errorf("%w: %s", ErrCommandNotFound, name)
+		}
+		command.help(command.flagSet(ctx.Output), ctx.Output)
+		return nil
+	}
+	ctx.Println("Commands:")
+	for _, command := range slf.GetCommands() {
+		ctx.Printf("  %-16s %s\n", command.Name, command.Description)
+	}
+	ctx.Println("\nUse \"help <command>\" for more information about a command.")
+	return nil
+}
@@ -0,0 +1,5 @@
+// Package admin 提供了基于 server.Server 的管理指令实现
+//   - 支持带有参数及标志的具名指令，标志通过标准库 flag 进行解析，并内置 help 指令输出帮助信息
+//   - 指令可通过标准输入、本地 Unix 套接字及带有鉴权的 HTTP 接口执行
+//   - 指令的处理函数将在服务器的消息循环中执行，与 server.PushSystemMessage 相同，无需考虑并发安全问题
+package admin
@@ -0,0 +1,12 @@
+package admin
+
+import "errors"
+
+var (
+	ErrCommandNotFound   = errors.New("command not found")
+	ErrCommandExists     = errors.New("command already exists")
+	ErrCommandTimeout    = errors.New("command execution timeout")
+	ErrUnterminatedQuote = errors.New("unterminated quoted string")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrConsoleNotRunning = errors.New("the server of console is not running")
+)
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"time"
+)
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultHTTPPattern = "/admin"
+)
+
+// Option 管理控制台选项
+type Option func(console *Console)
+
+// WithTimeout 设置指令执行的超时时间
+//   - 默认为 DefaultTimeout
+//   - 超时后将返回 ErrCommandTimeout，但不会中断仍在消息循环中执行的处理函数
+func WithTimeout(timeout time.Duration) Option {
+	return func(console *Console) {
+		if timeout > 0 {
+			console.timeout = timeout
+		}
+	}
+}
+
+// WithStdin 通过标准输入执行指令，执行结果将输出到标准输出
+//   - 与 server.Server.RegConsoleCommandEvent 不同，即便标准输入不是终端也将进行读取，适用于容器中通过 attach 执行指令的场景
+func WithStdin() Option {
+	return func(console *Console) {
+		console.transports = append(console.transports, &stdinTransport{})
+	}
+}
+
+// WithUnixSocket 通过本地 Unix 套接字执行指令
+//   - 每行为一条指令，执行结果将写回该连接
+//   - 套接字文件的权限为 0600，已存在的套接字文件将被移除
+func WithUnixSocket(path string) Option {
+	return func(console *Console) {
+		console.transports = append(console.transports, &unixTransport{path: path})
+	}
+}
+
+// WithHTTP 通过带有鉴权的 HTTP 接口执行指令
+//   - addr：独立侦听的地址
+//   - token：鉴权令牌，请求需携带 "Authorization: Bearer <token>" 请求头，不允许为空
+//   - pattern：接口的路由，默认为 DefaultHTTPPattern
+//
+// 指令可通过 POST 请求体或 command 查询参数传递，执行结果将以纯文本的形式返回
+func WithHTTP(addr, token string, pattern ...string) Option {
+	return func(console *Console) {
+		if len(token) == 0 {
+			panic(ErrUnauthorized)
+		}
+		var p = DefaultHTTPPattern
+		if len(pattern) > 0 && len(pattern[0]) > 0 {
+			p = pattern[0]
+		}
+		console.transports = append(console.transports, &httpTransport{addr: addr, token: token, pattern: p})
+	}
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"strings"
+)
+
+// splitArgs 将指令行拆分为参数，支持单引号、双引号及反斜杠转义
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		escape  bool
+		inArg   bool
+	)
+	for _, r := range line {
+		switch {
+		case escape:
+			current.WriteRune(r)
+			escape = false
+		case r == '\\' && quote != '\'':
+			escape, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escape {
+		return nil, ErrUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
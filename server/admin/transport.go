@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// transport 指令执行通道
+type transport interface {
+	fmt.Stringer
+	// start 开始运行指令执行通道
+	start(console *Console) error
+	// stop 停止运行指令执行通道
+	stop()
+}
+
+// serve 逐行读取指令并执行，执行结果及错误信息将写入 writer
+func serve(console *Console, reader io.Reader, writer io.Writer) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if err := console.Exec(scanner.Text(), writer); err != nil {
+			_, _ = fmt.Fprintf(writer, "error: %s\n", err)
+		}
+	}
+}
+
+// stdinTransport 标准输入指令执行通道
+type stdinTransport struct{}
+
+func (slf *stdinTransport) String() string {
+	return "stdin"
+}
+
+func (slf *stdinTransport) start(console *Console) error {
+	go serve(console, os.Stdin, os.Stdout)
+	return nil
+}
+
+func (slf *stdinTransport) stop() {}
+
+// unixTransport 本地 Unix 套接字指令执行通道
+type unixTransport struct {
+	path     string
+	listener net.Listener
+}
+
+func (slf *unixTransport) String() string {
+	return "unix://" + slf.path
+}
+
+func (slf *unixTransport) start(console *Console) error {
+	if info, err := os.Stat(slf.path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(slf.path)
+	}
+	listener, err := net.Listen("unix", slf.path)
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(slf.path, 0600); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	slf.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Error("Admin", log.String("transport", slf.String()), log.Err(err))
+				}
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				serve(console, conn, conn)
+			}(conn)
+		}
+	}()
+	return nil
+}
+
+func (slf *unixTransport) stop() {
+	if slf.listener != nil {
+		_ = slf.listener.Close()
+	}
+}
+
+// httpTransport 带有鉴权的 HTTP 指令执行通道
+type httpTransport struct {
+	addr    string
+	token   string
+	pattern string
+	server  *http.Server
+}
+
+func (slf *httpTransport) String() string {
+	return "http://" + slf.addr + slf.pattern
+}
+
+func (slf *httpTransport) start(console *Console) error {
+	listener, err := net.Listen("tcp", slf.addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(slf.pattern, func(writer http.ResponseWriter, request *http.Request) {
+		if !slf.authorized(request) {
+			http.Error(writer, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+		var command = request.URL.Query().Get("command")
+		if request.Method == http.MethodPost {
+			body, err := io.ReadAll(io.LimitReader(request.Body, 1<<20))
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if len(body) > 0 {
+				command = string(body)
+			}
+		}
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		var output strings.Builder
+		if err := console.Exec(command, &output); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrCommandNotFound) {
+				status = http.StatusNotFound
+			}
+			writer.WriteHeader(status)
+			_, _ = fmt.Fprintf(writer, "%serror: %s\n", output.String(), err)
+			return
+		}
+		_, _ = io.WriteString(writer, output.String())
+	})
+	slf.server = &http.Server{Handler: mux}
+	go func() {
+		if err := slf.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Admin", log.String("transport", slf.String()), log.Err(err))
+		}
+	}()
+	return nil
+}
+
+// authorized 检查请求是否携带了正确的鉴权令牌
+func (slf *httpTransport) authorized(request *http.Request) bool {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(slf.token)) == 1
+}
+
+func (slf *httpTransport) stop() {
+	if slf.server != nil {
+		_ = slf.server.Close()
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/xtaci/kcp-go/v5"
+	"sync/atomic"
+)
+
+// NewKCP 创建 KCP 客户端
+//   - options 需要与服务器通过 server.WithKCPOptions 设置的 Block、DataShards 及 ParityShards 一致，为 nil 时使用 kcp-go 的默认参数
+func NewKCP(addr string, options ...*server.KCPOptions) *Client {
+	core := &KCP{addr: addr}
+	if len(options) > 0 {
+		core.options = options[0]
+	}
+	return NewClient(core)
+}
+
+// KCP KCP 客户端
+type KCP struct {
+	addr    string
+	options *server.KCPOptions
+	conn    *kcp.UDPSession
+	closed  atomic.Bool
+}
+
+func (slf *KCP) Run(runState chan<- error, receive func(wst int, packet []byte)) {
+	var session *kcp.UDPSession
+	var err error
+	if slf.options == nil {
+		session, err = kcp.DialWithOptions(slf.addr, nil, 0, 0)
+	} else {
+		session, err = kcp.DialWithOptions(slf.addr, slf.options.Block, slf.options.DataShards, slf.options.ParityShards)
+	}
+	if err != nil {
+		runState <- err
+		return
+	}
+	slf.options.Apply(session)
+	if slf.options != nil && slf.options.SocketBuffer > 0 {
+		_ = session.SetReadBuffer(slf.options.SocketBuffer)
+		_ = session.SetWriteBuffer(slf.options.SocketBuffer)
+	}
+	slf.conn = session
+	slf.closed.Store(false)
+	runState <- nil
+	packet := make([]byte, slf.options.GetReadBufferSize())
+	for !slf.closed.Load() {
+		n, readErr := session.Read(packet)
+		if readErr != nil {
+			if slf.closed.Load() {
+				return
+			}
+			panic(readErr)
+		}
+		receive(0, packet[:n])
+	}
+}
+
+func (slf *KCP) Write(packet *Packet) error {
+	_, err := slf.conn.Write(packet.data)
+	return err
+}
+
+func (slf *KCP) Close() {
+	slf.closed.Store(true)
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
+}
+
+func (slf *KCP) GetServerAddr() string {
+	return slf.addr
+}
+
+func (slf *KCP) Clone() Core {
+	return &KCP{
+		addr:    slf.addr,
+		options: slf.options,
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/quic-go/quic-go"
+	"sync/atomic"
+)
+
+// NewQUIC 创建 QUIC 客户端
+//   - tlsConfig 为 nil 时将使用默认配置校验服务器证书，当服务器使用自签名证书时需要设置 InsecureSkipVerify 或 RootCAs
+//   - 未设置 NextProtos 时将使用 server.QUICProtocol
+//   - 数据包将通过 server.NewLengthFieldCodec 创建的编解码器进行封包及分包，与服务器的默认编解码器一致
+func NewQUIC(addr string, tlsConfig ...*tls.Config) *Client {
+	return NewQUICWithCodec(addr, server.NewLengthFieldCodec(), tlsConfig...)
+}
+
+// NewQUICWithCodec 创建使用特定编解码器的 QUIC 客户端，codec 需要与服务器通过 server.WithPacketCodec 设置的编解码器一致
+func NewQUICWithCodec(addr string, codec server.Codec, tlsConfig ...*tls.Config) *Client {
+	core := &QUIC{addr: addr, codec: codec}
+	if len(tlsConfig) > 0 {
+		core.tls = tlsConfig[0]
+	}
+	return NewClient(core)
+}
+
+// QUIC QUIC 客户端
+type QUIC struct {
+	addr   string
+	tls    *tls.Config
+	codec  server.Codec
+	conn   quic.Connection
+	stream quic.Stream
+	closed atomic.Bool
+}
+
+func (slf *QUIC) Run(runState chan<- error, receive func(wst int, packet []byte)) {
+	var config = new(tls.Config)
+	if slf.tls != nil {
+		config = slf.tls.Clone()
+	}
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{server.QUICProtocol}
+	}
+	conn, err := quic.DialAddr(context.Background(), slf.addr, config, nil)
+	if err != nil {
+		runState <- err
+		return
+	}
+	stream, err := conn.OpenStreamSync(context.Background())
+	if err == nil {
+		_, err = stream.Write([]byte{server.QUICStreamPreamble})
+	}
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		runState <- err
+		return
+	}
+	slf.conn, slf.stream = conn, stream
+	slf.closed.Store(false)
+	runState <- nil
+	var buffer []byte
+	packet := make([]byte, 4096)
+	for !slf.closed.Load() {
+		n, readErr := stream.Read(packet)
+		if readErr != nil {
+			if slf.closed.Load() {
+				return
+			}
+			panic(readErr)
+		}
+		buffer = append(buffer, packet[:n]...)
+		var offset int
+		for offset < len(buffer) {
+			data, size, decodeErr := slf.codec.Decode(buffer[offset:])
+			if decodeErr != nil {
+				panic(decodeErr)
+			}
+			if size <= 0 {
+				break
+			}
+			offset += size
+			receive(0, append([]byte(nil), data...))
+		}
+		buffer = append(buffer[:0], buffer[offset:]...)
+	}
+}
+
+func (slf *QUIC) Write(packet *Packet) error {
+	data, err := slf.codec.Encode(packet.data)
+	if err != nil {
+		return err
+	}
+	_, err = slf.stream.Write(data)
+	return err
+}
+
+func (slf *QUIC) Close() {
+	slf.closed.Store(true)
+	if slf.conn != nil {
+		_ = slf.conn.CloseWithError(0, "")
+	}
+}
+
+func (slf *QUIC) GetServerAddr() string {
+	return slf.addr
+}
+
+func (slf *QUIC) Clone() Core {
+	return &QUIC{
+		addr:  slf.addr,
+		tls:   slf.tls,
+		codec: slf.codec,
+	}
+}
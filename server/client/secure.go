@@ -0,0 +1,133 @@
+package client
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrSecureHandshakeTimeout = errors.New("the secure key exchange with the server did not complete in time")
+	ErrSecureNotEstablished   = errors.New("the secure session has not been established")
+)
+
+// NewSecure 创建基于 core 的加密传输客户端核心
+//   - 连接建立后将等待服务器发送的握手数据包并回复本地公钥，完成密钥交换后才会视为启动完成
+//   - 当发送的数据包数量达到 rotatePackets 或距上次轮换超过 rotateInterval 时将轮换密钥，为 0 时表示不限制
+//   - timeout 小于等于 0 时将使用 server.DefaultSecureHandshakeTimeout
+//   - 未设置 psk 时密钥交换未经认证，无法抵御中间人攻击；设置 psk 后需要与服务器通过 server.WithSecureTransport 设置的 psk 一致，用于确认服务器的身份
+//   - 需要传输层确保数据包边界，例如 Websocket
+func NewSecure(core Core, rotatePackets uint64, rotateInterval, timeout time.Duration, psk ...[]byte) *Secure {
+	if timeout <= 0 {
+		timeout = server.DefaultSecureHandshakeTimeout
+	}
+	s := &Secure{
+		core:           core,
+		rotatePackets:  rotatePackets,
+		rotateInterval: rotateInterval,
+		timeout:        timeout,
+	}
+	if len(psk) > 0 {
+		s.psk = psk[0]
+	}
+	return s
+}
+
+// Secure 加密传输客户端核心
+type Secure struct {
+	core           Core
+	rotatePackets  uint64
+	rotateInterval time.Duration
+	timeout        time.Duration
+	psk            []byte
+	session        atomic.Pointer[crypto.SecureSession]
+	ready          chan error
+}
+
+// EnableSecure 开启加密传输，需要在 Run 之前调用，服务器需要通过 server.WithSecureTransport 开启加密传输
+//   - 未设置 psk 时密钥交换未经认证，无法抵御中间人攻击，具体可参考 NewSecure
+func (slf *Client) EnableSecure(rotatePackets uint64, rotateInterval, timeout time.Duration, psk ...[]byte) *Client {
+	slf.core = NewSecure(slf.core, rotatePackets, rotateInterval, timeout, psk...)
+	return slf
+}
+
+func (slf *Secure) Run(runState chan<- error, receive func(wst int, packet []byte)) {
+	var state = make(chan error)
+	slf.ready = make(chan error, 1)
+	slf.session.Store(nil)
+	go func(ready <-chan error) {
+		err := <-state
+		if err == nil {
+			select {
+			case err = <-ready:
+			case <-time.After(slf.timeout):
+				err = ErrSecureHandshakeTimeout
+				slf.core.Close()
+			}
+		}
+		runState <- err
+	}(slf.ready)
+	slf.core.Run(state, func(wst int, packet []byte) {
+		if session := slf.session.Load(); session != nil {
+			data, err := session.Open(packet)
+			if err != nil {
+				panic(err)
+			}
+			receive(wst, data)
+			return
+		}
+		err := slf.exchange(packet)
+		slf.ready <- err
+		if err != nil {
+			panic(err)
+		}
+	})
+}
+
+// exchange 处理服务器的握手数据包并回复本地公钥
+func (slf *Secure) exchange(packet []byte) error {
+	cipher, public, err := crypto.DecodeSecureHandshake(packet)
+	if err != nil {
+		return err
+	}
+	private, err := crypto.NewSecureKeyPair()
+	if err != nil {
+		return err
+	}
+	session, err := crypto.NewSecureSession(cipher, private, public, false, slf.rotatePackets, slf.rotateInterval, slf.psk)
+	if err != nil {
+		return err
+	}
+	if err = slf.core.Write(&Packet{data: crypto.EncodeSecureHandshake(cipher, private.PublicKey())}); err != nil {
+		return err
+	}
+	slf.session.Store(session)
+	return nil
+}
+
+func (slf *Secure) Write(packet *Packet) error {
+	session := slf.session.Load()
+	if session == nil {
+		return ErrSecureNotEstablished
+	}
+	data, err := session.Seal(packet.data)
+	if err != nil {
+		return err
+	}
+	packet.data = data
+	return slf.core.Write(packet)
+}
+
+func (slf *Secure) Close() {
+	slf.core.Close()
+}
+
+func (slf *Secure) GetServerAddr() string {
+	return slf.core.GetServerAddr()
+}
+
+func (slf *Secure) Clone() Core {
+	return NewSecure(slf.core.Clone(), slf.rotatePackets, slf.rotateInterval, slf.timeout, slf.psk)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	DefaultLengthFieldMaxFrameLength = 1 << 20 // 默认长度字段编解码器数据包内容最大长度
+)
+
+// Codec 数据包编解码器，用于在流式传输的网络中对数据包进行封包及分包，避免出现半包、粘包的问题
+type Codec interface {
+	// Encode 对即将写入连接的数据包进行封包
+	Encode(packet []byte) ([]byte, error)
+
+	// Decode 从连接接收到的数据中解析出一个完整的数据包
+	//  - n 表示本次解析所消耗的字节数，当数据不足以构成一个完整的数据包时，应返回 n == 0 且 err == nil
+	//  - 返回的 packet 允许引用 data 中的内容，服务器在推送消息前会对其进行拷贝
+	Decode(data []byte) (packet []byte, n int, err error)
+}
+
+// NewLengthFieldCodec 创建一个基于长度字段的数据包编解码器
+//   - 数据包格式为：[长度字段][数据包内容]，长度字段仅表示数据包内容的长度
+//   - 默认长度字段为 4 字节，采用大端序，数据包内容最大长度为 DefaultLengthFieldMaxFrameLength
+func NewLengthFieldCodec(options ...LengthFieldCodecOption) *LengthFieldCodec {
+	codec := &LengthFieldCodec{
+		headerSize: 4,
+		byteOrder:  binary.BigEndian,
+	}
+	for _, option := range options {
+		option(codec)
+	}
+	if codec.maxFrameLength <= 0 {
+		codec.maxFrameLength = DefaultLengthFieldMaxFrameLength
+	}
+	switch codec.headerSize {
+	case 1, 2, 4, 8:
+	default:
+		panic(ErrCodecIllegalHeaderSize)
+	}
+	return codec
+}
+
+// LengthFieldCodecOption 长度字段编解码器可选项
+type LengthFieldCodecOption func(codec *LengthFieldCodec)
+
+// WithLengthFieldHeaderSize 通过特定的长度字段字节数创建编解码器
+//   - 仅支持 1、2、4、8
+func WithLengthFieldHeaderSize(size int) LengthFieldCodecOption {
+	return func(codec *LengthFieldCodec) {
+		codec.headerSize = size
+	}
+}
+
+// WithLengthFieldByteOrder 通过特定的字节序创建编解码器
+//   - 默认为 binary.BigEndian
+func WithLengthFieldByteOrder(byteOrder binary.ByteOrder) LengthFieldCodecOption {
+	return func(codec *LengthFieldCodec) {
+		if byteOrder != nil {
+			codec.byteOrder = byteOrder
+		}
+	}
+}
+
+// WithLengthFieldMaxFrameLength 通过限制数据包内容最大长度的方式创建编解码器
+//   - 当数据包内容长度超出该值时，编解码器将返回 ErrPacketTooLarge 错误
+//   - 当 length <= 0 时将使用默认值 DefaultLengthFieldMaxFrameLength，避免对端声明超大长度导致读取缓冲区无限增长
+func WithLengthFieldMaxFrameLength(length int) LengthFieldCodecOption {
+	return func(codec *LengthFieldCodec) {
+		codec.maxFrameLength = length
+	}
+}
+
+// LengthFieldCodec 基于长度字段的数据包编解码器
+type LengthFieldCodec struct {
+	headerSize     int              // 长度字段字节数
+	byteOrder      binary.ByteOrder // 字节序
+	maxFrameLength int              // 数据包内容最大长度
+}
+
+// Encode 对数据包进行封包
+func (slf *LengthFieldCodec) Encode(packet []byte) ([]byte, error) {
+	length := len(packet)
+	if err := slf.check(uint64(length)); err != nil {
+		return nil, err
+	}
+	data := make([]byte, slf.headerSize+length)
+	switch slf.headerSize {
+	case 1:
+		data[0] = byte(length)
+	case 2:
+		slf.byteOrder.PutUint16(data, uint16(length))
+	case 4:
+		slf.byteOrder.PutUint32(data, uint32(length))
+	case 8:
+		slf.byteOrder.PutUint64(data, uint64(length))
+	}
+	copy(data[slf.headerSize:], packet)
+	return data, nil
+}
+
+// Decode 从数据中解析出一个完整的数据包
+func (slf *LengthFieldCodec) Decode(data []byte) (packet []byte, n int, err error) {
+	if len(data) < slf.headerSize {
+		return nil, 0, nil
+	}
+	var length uint64
+	switch slf.headerSize {
+	case 1:
+		length = uint64(data[0])
+	case 2:
+		length = uint64(slf.byteOrder.Uint16(data))
+	case 4:
+		length = uint64(slf.byteOrder.Uint32(data))
+	case 8:
+		length = slf.byteOrder.Uint64(data)
+	}
+	if err = slf.check(length); err != nil {
+		return nil, 0, err
+	}
+	if uint64(len(data)-slf.headerSize) < length {
+		return nil, 0, nil
+	}
+	n = slf.headerSize + int(length)
+	return data[slf.headerSize:n], n, nil
+}
+
+// check 检查数据包内容长度是否合法
+func (slf *LengthFieldCodec) check(length uint64) error {
+	if length > uint64(slf.maxFrameLength) {
+		return fmt.Errorf("%w: %d > %d", ErrPacketTooLarge, length, slf.maxFrameLength)
+	}
+	if slf.headerSize < 8 && length >= 1<<(uint(slf.headerSize)*8) {
+		return fmt.Errorf("%w: %d overflow %d bytes header", ErrPacketTooLarge, length, slf.headerSize)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+)
+
+func TestLengthFieldCodec_Decode(t *testing.T) {
+	codec := server.NewLengthFieldCodec(server.WithLengthFieldHeaderSize(2), server.WithLengthFieldByteOrder(binary.LittleEndian))
+	var stream []byte
+	for _, packet := range []string{"Hello", "", "Minotaur"} {
+		data, err := codec.Encode([]byte(packet))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, data...)
+	}
+
+	var packets []string
+	var buffer []byte
+	for i := 0; i < len(stream); i++ {
+		buffer = append(buffer, stream[i])
+		for {
+			packet, n, err := codec.Decode(buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n == 0 {
+				break
+			}
+			packets = append(packets, string(packet))
+			buffer = buffer[n:]
+		}
+	}
+	if len(packets) != 3 || packets[0] != "Hello" || packets[1] != "" || packets[2] != "Minotaur" {
+		t.Fatal(packets)
+	}
+}
+
+func TestLengthFieldCodec_MaxFrameLength(t *testing.T) {
+	codec := server.NewLengthFieldCodec(server.WithLengthFieldMaxFrameLength(4))
+	if _, err := codec.Encode(bytes.Repeat([]byte{1}, 5)); !errors.Is(err, server.ErrPacketTooLarge) {
+		t.Fatal(err)
+	}
+	if _, _, err := codec.Decode([]byte{0, 0, 0, 5}); !errors.Is(err, server.ErrPacketTooLarge) {
+		t.Fatal(err)
+	}
+	if _, err := server.NewLengthFieldCodec(server.WithLengthFieldHeaderSize(1)).Encode(make([]byte, 256)); !errors.Is(err, server.ErrPacketTooLarge) {
+		t.Fatal(err)
+	}
+}
+
+func TestLengthFieldCodec_DefaultMaxFrameLength(t *testing.T) {
+	for _, codec := range []*server.LengthFieldCodec{server.NewLengthFieldCodec(), server.NewLengthFieldCodec(server.WithLengthFieldMaxFrameLength(0))} {
+		if _, _, err := codec.Decode([]byte{0xff, 0xff, 0xff, 0xff}); !errors.Is(err, server.ErrPacketTooLarge) {
+			t.Fatal(err)
+		}
+		if _, err := codec.Encode(make([]byte, server.DefaultLengthFieldMaxFrameLength)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
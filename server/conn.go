@@ -1,14 +1,22 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/utils/concurrent"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"github.com/kercylan98/minotaur/utils/slice"
 	"github.com/panjf2000/gnet"
+	"github.com/quic-go/quic-go"
 	"github.com/xtaci/kcp-go/v5"
+	"golang.org/x/time/rate"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // newKcpConn 创建一个处理KCP的连接
@@ -16,17 +24,64 @@ func newKcpConn(server *Server, session *kcp.UDPSession) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			packets:    make(chan *connPacket, 1024*10),
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
 			server:     server,
 			remoteAddr: session.RemoteAddr(),
-			ip:         session.RemoteAddr().String(),
+			ip:         ipOf(session.RemoteAddr()),
 			kcp:        session,
 			data:       map[any]any{},
 		},
 	}
-	if index := strings.LastIndex(c.ip, ":"); index != -1 {
-		c.ip = c.ip[0:index]
+	c.ipAcquired = true
+	c.initRateLimit()
+	c.touch()
+	var wait = new(sync.WaitGroup)
+	wait.Add(1)
+	go c.writeLoop(wait)
+	wait.Wait()
+	return c
+}
+
+// newQUICConn 创建一个处理QUIC的连接
+func newQUICConn(server *Server, qc quic.Connection, stream quic.Stream) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
+			server:     server,
+			remoteAddr: qc.RemoteAddr(),
+			ip:         ipOf(qc.RemoteAddr()),
+			quic:       qc,
+			quicStream: stream,
+			data:       map[any]any{},
+		},
 	}
+	c.ipAcquired = true
+	c.initRateLimit()
+	c.touch()
+	var wait = new(sync.WaitGroup)
+	wait.Add(1)
+	go c.writeLoop(wait)
+	wait.Wait()
+	return c
+}
+
+// newTCPConn 创建一个处理基于 net.Listener 的 TCP 连接
+func newTCPConn(server *Server, conn net.Conn) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
+			server:     server,
+			remoteAddr: conn.RemoteAddr(),
+			ip:         ipOf(conn.RemoteAddr()),
+			tcp:        conn,
+			data:       map[any]any{},
+		},
+	}
+	c.ipAcquired = true
+	c.initRateLimit()
+	c.touch()
 	var wait = new(sync.WaitGroup)
 	wait.Add(1)
 	go c.writeLoop(wait)
@@ -39,17 +94,17 @@ func newGNetConn(server *Server, conn gnet.Conn) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			packets:    make(chan *connPacket, 1024*10),
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
 			server:     server,
 			remoteAddr: conn.RemoteAddr(),
-			ip:         conn.RemoteAddr().String(),
+			ip:         ipOf(conn.RemoteAddr()),
 			gn:         conn,
 			data:       map[any]any{},
 		},
 	}
-	if index := strings.LastIndex(c.ip, ":"); index != -1 {
-		c.ip = c.ip[0:index]
-	}
+	c.ipAcquired = true
+	c.initRateLimit()
+	c.touch()
 	var wait = new(sync.WaitGroup)
 	wait.Add(1)
 	go c.writeLoop(wait)
@@ -58,18 +113,22 @@ func newGNetConn(server *Server, conn gnet.Conn) *Conn {
 }
 
 // newKcpConn 创建一个处理WebSocket的连接
-func newWebsocketConn(server *Server, ws *websocket.Conn, ip string) *Conn {
+func newWebsocketConn(server *Server, ws *websocket.Conn, ip string, request *http.Request) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			packets:    make(chan *connPacket, 1024*10),
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
 			server:     server,
 			remoteAddr: ws.RemoteAddr(),
 			ip:         ip,
 			ws:         ws,
+			wsRequest:  request,
 			data:       map[any]any{},
+			ipAcquired: true,
 		},
 	}
+	c.initRateLimit()
+	c.touch()
 	var wait = new(sync.WaitGroup)
 	wait.Add(1)
 	go c.writeLoop(wait)
@@ -82,7 +141,7 @@ func newGatewayConn(conn *Conn, connId string) *Conn {
 	c := &Conn{
 		//ctx: server.ctx,
 		connection: &connection{
-			packets: make(chan *connPacket, 1024*10),
+			packets: make(chan *connPacket, conn.server.getWriteQueueSize()),
 			server:  conn.server,
 			data:    map[any]any{},
 		},
@@ -98,13 +157,14 @@ func NewEmptyConn(server *Server) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			packets:    make(chan *connPacket, 1024*10),
+			packets:    make(chan *connPacket, server.getWriteQueueSize()),
 			server:     server,
 			remoteAddr: &net.TCPAddr{},
 			ip:         "0.0.0.0:0",
 			data:       map[any]any{},
 		},
 	}
+	c.touch()
 	var wait = new(sync.WaitGroup)
 	wait.Add(1)
 	go c.writeLoop(wait)
@@ -112,6 +172,15 @@ func NewEmptyConn(server *Server) *Conn {
 	return c
 }
 
+// ipOf 获取网络地址中的IP部分
+func ipOf(addr net.Addr) string {
+	ip := addr.String()
+	if index := strings.LastIndex(ip, ":"); index != -1 {
+		ip = ip[0:index]
+	}
+	return ip
+}
+
 // Conn 服务器连接单次会话的包装
 type Conn struct {
 	*connection
@@ -123,21 +192,39 @@ type connection struct {
 	server     *Server
 	close      sync.Once
 	closed     bool
-	closeL     sync.Mutex
+	closeL     sync.RWMutex
 	remoteAddr net.Addr
 	ip         string
 	ws         *websocket.Conn
+	wsRequest  *http.Request
 	gn         gnet.Conn
+	tcp        net.Conn
 	kcp        *kcp.UDPSession
+	quic       quic.Connection
+	quicStream quic.Stream
 	gw         func(packet []byte)
 	data       map[any]any
 	packetPool *concurrent.Pool[*connPacket]
 	packets    chan *connPacket
+	readBuffer []byte // 编解码器分包时使用的读取缓冲区
+
+	packetLimiter *rate.Limiter // 数据包数量限流器
+	byteLimiter   *rate.Limiter // 字节数限流器
+	limitedAt     time.Time     // 最近一次触发限流事件的时间
+	ipAcquired    bool          // 是否占用了IP连接数
+	lastReceive   atomic.Int64  // 最近一次接收到数据的时间
+	lastPing      time.Time     // 最近一次发送心跳数据包的时间
+	pendingBytes  atomic.Int64  // 写入队列中尚未写入的字节数
+	slowAt        atomic.Int64  // 最近一次触发写入缓慢事件的时间
+	writeAbort    chan struct{} // 连接关闭时用于中断阻塞的写入
+	handshake     *handshake    // 握手状态，未设置握手时为 nil
+	shunt         connShunt     // 分流通道绑定状态
+	secure        *connSecure   // 加密传输状态，未设置加密传输时为 nil
 }
 
 // IsEmpty 是否是空连接
 func (slf *Conn) IsEmpty() bool {
-	return slf.ws == nil && slf.gn == nil && slf.kcp == nil && slf.gw == nil
+	return slf.ws == nil && slf.gn == nil && slf.tcp == nil && slf.kcp == nil && slf.quic == nil && slf.gw == nil
 }
 
 // RemoteAddr 获取远程地址
@@ -172,6 +259,12 @@ func (slf *Conn) GetData(key any) any {
 	return slf.data[key]
 }
 
+// GetDataAll 获取连接的所有数据
+//   - 返回的为数据的拷贝，对其修改不会影响连接数据
+func (slf *Conn) GetDataAll() map[any]any {
+	return hash.Copy(slf.data)
+}
+
 // SetMessageData 设置消息数据，该数据将在消息处理完成后释放
 func (slf *Conn) SetMessageData(key, value any) *Conn {
 	slf.ctx = context.WithValue(slf.ctx, key, value)
@@ -191,6 +284,12 @@ func (slf *Conn) ReleaseData() *Conn {
 	return slf
 }
 
+// GetWebsocketRequest 获取websocket连接升级时的请求，非websocket连接将返回 nil
+//   - 可通过请求的路径区分通过 WithWebsocketPaths 设置的不同升级路径
+func (slf *Conn) GetWebsocketRequest() *http.Request {
+	return slf.wsRequest
+}
+
 // IsWebsocket 是否是websocket连接
 func (slf *Conn) IsWebsocket() bool {
 	return slf.server.network == NetworkWebsocket
@@ -210,15 +309,23 @@ func (slf *Conn) SetWST(wst int) *Conn {
 
 // Write 向连接中写入数据
 //   - messageType: websocket模式中指定消息类型
+//   - 当写入队列已满时，将根据 WithConnectionWriteQueue 配置的策略进行处理，未能写入的数据包将通过 callback 返回 ErrConnectionWriteQueueFull
 func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
+	if slf.server.recorder != nil {
+		slf.server.recorder.record(slf, PacketRecordOutbound, slf.GetWST(), packet)
+	}
 	if slf.gw != nil {
 		slf.gw(packet)
 		return
 	}
-	packet = slf.server.OnConnectionWritePacketBeforeEvent(slf, packet)
-	slf.closeL.Lock()
-	defer slf.closeL.Unlock()
+	slf.write(slf.server.OnConnectionWritePacketBeforeEvent(slf, packet), callback...)
+}
+
+// write 将数据包放入写入队列，不会触发 ConnectionWritePacketBeforeEvent 及数据包录制
+func (slf *Conn) write(packet []byte, callback ...func(err error)) {
+	slf.closeL.RLock()
 	if slf.packetPool == nil || slf.packets == nil {
+		slf.closeL.RUnlock()
 		return
 	}
 	cp := slf.packetPool.Get()
@@ -227,12 +334,17 @@ func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
 	if len(callback) > 0 {
 		cp.callback = callback[0]
 	}
-	slf.packets <- cp
+	closing := slf.enqueue(cp)
+	slf.closeL.RUnlock()
+	if closing {
+		slf.Close(ErrConnectionWriteQueueFull)
+	}
 }
 
 // writeLoop 写循环
 func (slf *Conn) writeLoop(wait *sync.WaitGroup) {
-	slf.packetPool = concurrent.NewPool[*connPacket](10*1024,
+	slf.writeAbort = make(chan struct{})
+	slf.packetPool = concurrent.NewPool[*connPacket](slf.server.getWriteQueueSize(),
 		func() *connPacket {
 			return &connPacket{}
 		}, func(data *connPacket) {
@@ -256,25 +368,45 @@ func (slf *Conn) writeLoop(wait *sync.WaitGroup) {
 		}
 
 		data := packet
+		size := int64(len(data.packet))
 		var err error
-		if slf.IsWebsocket() {
+		data.packet, err = slf.seal(data.packet)
+		switch {
+		case err != nil:
+		case slf.IsWebsocket():
+			if data.wst == 0 {
+				data.wst = WebsocketMessageTypeBinary
+			}
 			err = slf.ws.WriteMessage(data.wst, data.packet)
-		} else {
-			if slf.gn != nil {
+		default:
+			if slf.server.packetCodec != nil {
+				data.packet, err = slf.server.packetCodec.Encode(data.packet)
+			}
+			switch {
+			case err != nil:
+			case slf.gn != nil:
 				switch slf.server.network {
 				case NetworkUdp, NetworkUdp4, NetworkUdp6:
 					err = slf.gn.SendTo(data.packet)
 				default:
 					err = slf.gn.AsyncWrite(data.packet)
 				}
-			} else if slf.kcp != nil {
+			case slf.tcp != nil:
+				_, err = slf.tcp.Write(data.packet)
+			case slf.kcp != nil:
 				_, err = slf.kcp.Write(data.packet)
+			case slf.quic != nil:
+				_, err = slf.quicStream.Write(data.packet)
 			}
 		}
+		slf.pendingBytes.Add(-size)
+		if err == nil {
+			slf.server.metrics.written(len(data.packet))
+		}
 		callback := data.callback
-		slf.closeL.Lock()
+		slf.closeL.RLock()
 		slf.packetPool.Release(data)
-		slf.closeL.Unlock()
+		slf.closeL.RUnlock()
 		if callback != nil {
 			callback(err)
 		}
@@ -284,18 +416,90 @@ func (slf *Conn) writeLoop(wait *sync.WaitGroup) {
 	}
 }
 
+// receive 处理连接接收到的数据，当服务器设置了编解码器时将进行分包处理
+func (slf *Conn) receive(wst int, data []byte) error {
+	slf.server.metrics.received(len(data))
+	codec := slf.server.packetCodec
+	if codec == nil {
+		slf.pushPacket(wst, bytes.Clone(data))
+		return nil
+	}
+	slf.touch()
+	if slf.isUDPSession() {
+		// 数据报之间不会出现粘包，每个数据报需要独立分包，无法构成完整数据包的剩余数据将被丢弃
+		for len(data) > 0 {
+			packet, n, err := codec.Decode(data)
+			if err != nil {
+				return err
+			}
+			if n <= 0 {
+				break
+			}
+			data = data[n:]
+			slf.pushPacket(wst, bytes.Clone(packet))
+		}
+		return nil
+	}
+	slf.readBuffer = append(slf.readBuffer, data...)
+	var offset int
+	defer func() {
+		if offset > 0 {
+			slf.readBuffer = append(slf.readBuffer[:0], slf.readBuffer[offset:]...)
+		}
+	}()
+	for offset < len(slf.readBuffer) {
+		packet, n, err := codec.Decode(slf.readBuffer[offset:])
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			break
+		}
+		offset += n
+		slf.pushPacket(wst, bytes.Clone(packet))
+	}
+	return nil
+}
+
+// pushPacket 将连接接收到的完整数据包推送到服务器，当服务器设置了连接限流时将进行限流处理
+func (slf *Conn) pushPacket(wst int, packet []byte) {
+	slf.server.metrics.receivedPacket()
+	slf.touch()
+	if !slf.allow(len(packet)) {
+		return
+	}
+	if slf.secure != nil {
+		var ok bool
+		if packet, ok = slf.unseal(packet); !ok {
+			return
+		}
+	}
+	PushPacketMessage(slf.server, slf, wst, packet)
+}
+
 // Close 关闭连接
 func (slf *Conn) Close(err ...error) {
 	slf.close.Do(func() {
+		if slf.writeAbort != nil {
+			close(slf.writeAbort)
+		}
 		slf.closeL.Lock()
 		defer slf.closeL.Unlock()
 		slf.closed = true
 		if slf.ws != nil {
 			_ = slf.ws.Close()
 		} else if slf.gn != nil {
-			_ = slf.gn.Close()
+			if slf.isUDPSession() {
+				slf.server.gServer.freeUDPSession(slf)
+			} else {
+				_ = slf.gn.Close()
+			}
+		} else if slf.tcp != nil {
+			_ = slf.tcp.Close()
 		} else if slf.kcp != nil {
 			_ = slf.kcp.Close()
+		} else if slf.quic != nil {
+			_ = slf.quic.CloseWithError(quicCloseNormal, "")
 		}
 		if slf.packetPool != nil {
 			slf.packetPool.Close()
@@ -304,6 +508,20 @@ func (slf *Conn) Close(err ...error) {
 		if slf.packets != nil {
 			close(slf.packets)
 		}
+		if slf.ipAcquired {
+			slf.server.releaseIP(slf.ip)
+		}
+		var failed bool
+		if cs := slf.secure; cs != nil {
+			failed = cs.abort()
+		}
+		if hs := slf.handshake; hs != nil {
+			failed = hs.abort() || failed
+		}
+		if failed {
+			slf.server.OnConnectionHandshakeFailedEvent(slf, slice.GetValue(err, 0))
+			return
+		}
 		if len(err) > 0 {
 			slf.server.OnConnectionClosedEvent(slf, err[0])
 			return
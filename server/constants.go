@@ -25,6 +25,7 @@ const (
 	DefaultMessageChannelSize    = 1024 * 1024
 	DefaultAsyncPoolSize         = 256
 	DefaultWebsocketReadDeadline = 30 * time.Second
+	DefaultConnWriteQueueSize    = 1024 * 10
 )
 
 const (
@@ -1,15 +1,27 @@
 package server
 
+import "time"
+
 // Cross 跨服接口
 type Cross interface {
 	// Init 初始化跨服
 	//  - serverId: 本服id
 	//  - packetHandle.serverId: 发送跨服消息的服务器id
 	//  - packetHandle.packet: 数据包
-	Init(server *Server, packetHandle func(serverId int64, packet []byte)) error
+	//  - requestHandle.reply: 用于回复请求方的函数，跨服请求的处理结果应通过该函数返回
+	Init(server *Server, packetHandle func(serverId int64, packet []byte), requestHandle func(serverId int64, packet []byte, reply func(packet []byte) error)) error
 	// PushMessage 推送跨服消息
 	//  - serverId: 目标服务器id
 	PushMessage(serverId int64, packet []byte) error
+	// Request 向特定服务器发送跨服请求，并阻塞等待回复
+	//  - serverId: 目标服务器id
+	//  - timeout: 等待回复的超时时间
+	Request(serverId int64, packet []byte, timeout time.Duration) ([]byte, error)
+	// Broadcast 向所有服务器推送跨服消息，包含本服
+	Broadcast(packet []byte) error
+	// PushGroupMessage 向特定分组中的所有服务器推送跨服消息
+	//  - group: 分组名称，例如按照区域划分的服务器分组
+	PushGroupMessage(group string, packet []byte) error
 	// Release 释放资源
 	Release()
 }
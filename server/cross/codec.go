@@ -0,0 +1,60 @@
+package cross
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrMessageTooShort = errors.New("cross message is too short")
+)
+
+// Codec 跨服消息的序列化方式
+type Codec interface {
+	// Marshal 序列化跨服消息
+	Marshal(message *Message) ([]byte, error)
+	// Unmarshal 反序列化跨服消息
+	Unmarshal(data []byte, message *Message) error
+}
+
+// BinaryCodec 基于二进制信封的序列化方式，数据包将以原始字节的形式传输
+//   - | serverId(8) | sendTime(8) | traceIdLength(1) | traceId | packet |
+type BinaryCodec struct{}
+
+func (BinaryCodec) Marshal(message *Message) ([]byte, error) {
+	traceId := message.TraceId
+	if len(traceId) > 255 {
+		traceId = traceId[:255]
+	}
+	data := make([]byte, 17+len(traceId)+len(message.Packet))
+	binary.BigEndian.PutUint64(data[0:8], uint64(message.ServerId))
+	binary.BigEndian.PutUint64(data[8:16], uint64(message.SendTime))
+	data[16] = byte(len(traceId))
+	copy(data[17:], traceId)
+	copy(data[17+len(traceId):], message.Packet)
+	return data, nil
+}
+
+func (BinaryCodec) Unmarshal(data []byte, message *Message) error {
+	if len(data) < 17 || len(data) < 17+int(data[16]) {
+		return ErrMessageTooShort
+	}
+	message.ServerId = int64(binary.BigEndian.Uint64(data[0:8]))
+	message.SendTime = int64(binary.BigEndian.Uint64(data[8:16]))
+	message.TraceId = string(data[17 : 17+int(data[16])])
+	message.Packet = data[17+int(data[16]):]
+	return nil
+}
+
+// JSONCodec 基于 JSON 的序列化方式，数据包将被 base64 编码
+//   - 适用于与旧版本的跨服消息保持兼容
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(message *Message) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+func (JSONCodec) Unmarshal(data []byte, message *Message) error {
+	return json.Unmarshal(data, message)
+}
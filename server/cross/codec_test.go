@@ -0,0 +1,25 @@
+package cross_test
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/server/cross"
+	"testing"
+)
+
+func TestBinaryCodec_Unmarshal(t *testing.T) {
+	var codec = cross.BinaryCodec{}
+	data, err := codec.Marshal(&cross.Message{ServerId: 1, Packet: []byte{0, 1, 2}, TraceId: "trace", SendTime: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message cross.Message
+	if err = codec.Unmarshal(data, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.ServerId != 1 || message.TraceId != "trace" || message.SendTime != 100 || !bytes.Equal(message.Packet, []byte{0, 1, 2}) {
+		t.Fatal(message)
+	}
+	if err = codec.Unmarshal(data[:10], &message); err == nil {
+		t.Fatal("expect error")
+	}
+}
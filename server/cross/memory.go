@@ -0,0 +1,192 @@
+package cross
+
+import (
+	"bytes"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/random"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMemoryNodeNotFound   = errors.New("the target server is not joined to the memory cross")
+	ErrMemoryRequestTimeout = errors.New("memory cross request timeout")
+)
+
+// NewMemory 创建一个进程内的跨服中心，适用于测试及单进程部署多个服务器的情况
+//   - 同一进程中的多个服务器应通过 Memory.Node 获取各自的跨服节点，并通过 server.WithCross 使用
+func NewMemory(options ...MemoryOption) *Memory {
+	m := &Memory{
+		nodes: make(map[int64]*MemoryNode),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// Memory 进程内的跨服中心
+type Memory struct {
+	nodes      map[int64]*MemoryNode
+	rw         sync.RWMutex
+	latencyMin time.Duration
+	latencyMax time.Duration
+	dropRate   float64
+}
+
+// Node 创建一个加入该跨服中心的跨服节点
+//   - groups: 节点所加入的分组，支持与 Nats 相同的通配符，例如 "asia.*" 或 "asia.>"
+func (slf *Memory) Node(groups ...string) *MemoryNode {
+	return &MemoryNode{
+		memory: slf,
+		groups: groups,
+	}
+}
+
+// deliver 模拟网络延迟及丢包后执行投递
+func (slf *Memory) deliver(handle func()) bool {
+	if slf.dropRate > 0 && random.Float64() < slf.dropRate {
+		return false
+	}
+	if slf.latencyMax <= 0 {
+		handle()
+		return true
+	}
+	time.AfterFunc(random.Duration(int64(slf.latencyMin), int64(slf.latencyMax)), handle)
+	return true
+}
+
+// nodesWith 获取满足条件的所有节点
+func (slf *Memory) nodesWith(filter func(node *MemoryNode) bool) []*MemoryNode {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	var nodes = make([]*MemoryNode, 0, len(slf.nodes))
+	for _, node := range slf.nodes {
+		if filter(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// MemoryNode 进程内跨服中心的跨服节点，实现了 server.Cross 接口
+type MemoryNode struct {
+	memory        *Memory
+	serverId      int64
+	groups        []string
+	packetHandle  func(serverId int64, packet []byte)
+	requestHandle func(serverId int64, packet []byte, reply func(packet []byte) error)
+}
+
+func (slf *MemoryNode) Init(server *server.Server, packetHandle func(serverId int64, packet []byte), requestHandle func(serverId int64, packet []byte, reply func(packet []byte) error)) error {
+	slf.serverId = server.GetID()
+	slf.packetHandle = packetHandle
+	slf.requestHandle = requestHandle
+	slf.memory.rw.Lock()
+	slf.memory.nodes[slf.serverId] = slf
+	slf.memory.rw.Unlock()
+	return nil
+}
+
+func (slf *MemoryNode) PushMessage(serverId int64, packet []byte) error {
+	slf.memory.rw.RLock()
+	node, exist := slf.memory.nodes[serverId]
+	slf.memory.rw.RUnlock()
+	if !exist {
+		return ErrMemoryNodeNotFound
+	}
+	slf.push(node, packet)
+	return nil
+}
+
+func (slf *MemoryNode) Request(serverId int64, packet []byte, timeout time.Duration) ([]byte, error) {
+	slf.memory.rw.RLock()
+	node, exist := slf.memory.nodes[serverId]
+	slf.memory.rw.RUnlock()
+	if !exist {
+		return nil, ErrMemoryNodeNotFound
+	}
+	var wait = make(chan []byte, 1)
+	var senderId = slf.serverId
+	var data = bytes.Clone(packet)
+	slf.memory.deliver(func() {
+		node.requestHandle(senderId, data, func(packet []byte) error {
+			reply := bytes.Clone(packet)
+			slf.memory.deliver(func() {
+				select {
+				case wait <- reply:
+				default:
+				}
+			})
+			return nil
+		})
+	})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-wait:
+		return reply, nil
+	case <-timer.C:
+		return nil, ErrMemoryRequestTimeout
+	}
+}
+
+func (slf *MemoryNode) Broadcast(packet []byte) error {
+	for _, node := range slf.memory.nodesWith(func(node *MemoryNode) bool { return true }) {
+		slf.push(node, packet)
+	}
+	return nil
+}
+
+func (slf *MemoryNode) PushGroupMessage(group string, packet []byte) error {
+	for _, node := range slf.memory.nodesWith(func(node *MemoryNode) bool { return node.inGroup(group) }) {
+		slf.push(node, packet)
+	}
+	return nil
+}
+
+func (slf *MemoryNode) Release() {
+	slf.memory.rw.Lock()
+	if node, exist := slf.memory.nodes[slf.serverId]; exist && node == slf {
+		delete(slf.memory.nodes, slf.serverId)
+	}
+	slf.memory.rw.Unlock()
+}
+
+// push 向特定节点投递跨服消息
+func (slf *MemoryNode) push(node *MemoryNode, packet []byte) {
+	var senderId = slf.serverId
+	var data = bytes.Clone(packet)
+	slf.memory.deliver(func() {
+		node.packetHandle(senderId, data)
+	})
+}
+
+// inGroup 检查节点是否加入了特定分组
+func (slf *MemoryNode) inGroup(group string) bool {
+	for _, pattern := range slf.groups {
+		if matchSubject(pattern, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSubject 以 nats 的通配符规则检查主题是否匹配
+//   - "*" 匹配单个层级，">" 匹配之后的所有层级
+func matchSubject(pattern, subject string) bool {
+	patterns := strings.Split(pattern, ".")
+	subjects := strings.Split(subject, ".")
+	for i, p := range patterns {
+		if p == ">" {
+			return len(subjects) > i
+		}
+		if i >= len(subjects) || (p != "*" && p != subjects[i]) {
+			return false
+		}
+	}
+	return len(patterns) == len(subjects)
+}
@@ -0,0 +1,24 @@
+package cross
+
+import "time"
+
+type MemoryOption func(m *Memory)
+
+// WithMemoryLatency 通过模拟网络延迟的方式创建
+//   - 每条跨服消息将随机延迟 min ~ max 后送达，跨服请求的回复同样会受到延迟影响
+func WithMemoryLatency(min, max time.Duration) MemoryOption {
+	return func(m *Memory) {
+		if max < min {
+			min, max = max, min
+		}
+		m.latencyMin, m.latencyMax = min, max
+	}
+}
+
+// WithMemoryDropRate 通过模拟丢包的方式创建
+//   - rate 为 0 ~ 1 之间的丢包率，被丢弃的跨服请求将在超时后返回 ErrMemoryRequestTimeout
+func WithMemoryDropRate(rate float64) MemoryOption {
+	return func(m *Memory) {
+		m.dropRate = rate
+	}
+}
@@ -0,0 +1,54 @@
+package cross_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cross"
+	"testing"
+	"time"
+)
+
+func TestMemory_Request(t *testing.T) {
+	var (
+		hub    = cross.NewMemory(cross.WithMemoryLatency(time.Millisecond, 5*time.Millisecond))
+		result = make(chan string, 2)
+		a      = server.New(server.NetworkNone, server.WithCross("memory", 1, hub.Node()))
+		b      = server.New(server.NetworkNone, server.WithCross("memory", 2, hub.Node("asia.*")))
+	)
+	b.RegReceiveCrossPacketEvent(func(srv *server.Server, senderServerId int64, packet []byte) {
+		result <- string(packet)
+	})
+	b.RegReceiveCrossRequestEvent(func(srv *server.Server, senderServerId int64, packet []byte, reply func(packet []byte) error) {
+		_ = reply(append(packet, '!'))
+	})
+	a.RegMessageReadyEvent(func(srv *server.Server) {
+		server.PushCrossGroupMessage(srv, "memory", "asia.cn", []byte("group"))
+		server.PushCrossRequest(srv, "memory", 2, []byte("ping"), time.Second, func(reply []byte, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			result <- string(reply)
+		})
+	})
+	for _, srv := range []*server.Server{b, a} {
+		go func(srv *server.Server) {
+			if err := srv.RunNone(); err != nil {
+				t.Error(err)
+			}
+		}(srv)
+	}
+
+	var received = map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-result:
+			received[r] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	if !received["group"] || !received["ping!"] {
+		t.Fatal(received)
+	}
+	a.Shutdown()
+	b.Shutdown()
+}
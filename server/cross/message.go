@@ -1,6 +1,9 @@
 package cross
 
+// Message 跨服消息
 type Message struct {
-	ServerId int64  `json:"server_id"`
-	Packet   []byte `json:"packet"`
+	ServerId int64  `json:"server_id"`           // 发送方服务器id
+	Packet   []byte `json:"packet"`              // 数据包
+	TraceId  string `json:"trace_id,omitempty"`  // 追踪id
+	SendTime int64  `json:"send_time,omitempty"` // 发送时间（Unix 纳秒）
 }
@@ -1,7 +1,8 @@
 package cross
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/utils/concurrent"
@@ -18,11 +19,14 @@ func NewNats(url string, options ...NatsOption) *Nats {
 	n := &Nats{
 		url:     url,
 		subject: "MINOTAUR_CROSS",
+		codec:   BinaryCodec{},
 		messagePool: concurrent.NewPool[*Message](1024*100, func() *Message {
 			return new(Message)
 		}, func(data *Message) {
 			data.ServerId = 0
 			data.Packet = nil
+			data.TraceId = ""
+			data.SendTime = 0
 		}),
 	}
 	for _, option := range options {
@@ -37,9 +41,13 @@ type Nats struct {
 	subject     string
 	options     []nats.Option
 	messagePool *concurrent.Pool[*Message]
+	codec       Codec
+	latency     func(serverId int64, traceId string, latency time.Duration)
+	serverId    int64
+	groups      []string
 }
 
-func (slf *Nats) Init(server *server.Server, packetHandle func(serverId int64, packet []byte)) (err error) {
+func (slf *Nats) Init(server *server.Server, packetHandle func(serverId int64, packet []byte), requestHandle func(serverId int64, packet []byte, reply func(packet []byte) error)) (err error) {
 	if slf.conn == nil {
 		if len(slf.options) == 0 {
 			slf.options = append(slf.options,
@@ -58,30 +66,126 @@ func (slf *Nats) Init(server *server.Server, packetHandle func(serverId int64, p
 			return err
 		}
 	}
-	_, err = slf.conn.Subscribe(fmt.Sprintf("%s_%d", slf.subject, server.GetID()), func(msg *nats.Msg) {
+	slf.serverId = server.GetID()
+	handle := func(msg *nats.Msg) {
 		message := slf.messagePool.Get()
 		defer slf.messagePool.Release(message)
-		if err := json.Unmarshal(msg.Data, &message); err != nil {
+		if err := slf.codec.Unmarshal(msg.Data, message); err != nil {
 			log.Error(nasMark, log.Err(err))
 			return
 		}
-		packetHandle(message.ServerId, message.Packet)
-	})
-	return err
+		if slf.latency != nil && message.SendTime > 0 {
+			slf.latency(message.ServerId, message.TraceId, time.Duration(time.Now().UnixNano()-message.SendTime))
+		}
+		if len(msg.Reply) == 0 {
+			packetHandle(message.ServerId, message.Packet)
+			return
+		}
+		traceId := message.TraceId
+		requestHandle(message.ServerId, message.Packet, func(packet []byte) error {
+			data, err := slf.marshal(traceId, packet)
+			if err != nil {
+				return err
+			}
+			return msg.Respond(data)
+		})
+	}
+	subjects := []string{slf.directSubject(slf.serverId), slf.broadcastSubject()}
+	for _, group := range slf.groups {
+		subjects = append(subjects, slf.groupSubject(group))
+	}
+	for _, subject := range subjects {
+		if _, err = slf.conn.Subscribe(subject, handle); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (slf *Nats) PushMessage(serverId int64, packet []byte) error {
+	return slf.PushMessageWithTrace(serverId, generateTraceId(), packet)
+}
+
+// PushMessageWithTrace 通过特定的追踪id推送跨服消息
+//   - 追踪id及发送时间将随消息一同发送，接收方可通过 WithNatsLatency 获取消息的传输耗时
+func (slf *Nats) PushMessageWithTrace(serverId int64, traceId string, packet []byte) error {
+	data, err := slf.marshal(traceId, packet)
+	if err != nil {
+		return err
+	}
+	return slf.conn.Publish(slf.directSubject(serverId), data)
+}
+
+// Request 通过 nats 的请求/回复模式向特定服务器发送跨服请求
+func (slf *Nats) Request(serverId int64, packet []byte, timeout time.Duration) ([]byte, error) {
+	data, err := slf.marshal(generateTraceId(), packet)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := slf.conn.Request(slf.directSubject(serverId), data, timeout)
+	if err != nil {
+		return nil, err
+	}
 	message := slf.messagePool.Get()
 	defer slf.messagePool.Release(message)
-	message.ServerId = serverId
-	message.Packet = packet
-	data, err := json.Marshal(message)
+	if err = slf.codec.Unmarshal(msg.Data, message); err != nil {
+		return nil, err
+	}
+	return message.Packet, nil
+}
+
+// Broadcast 向所有订阅了相同主题的服务器推送跨服消息
+func (slf *Nats) Broadcast(packet []byte) error {
+	data, err := slf.marshal(generateTraceId(), packet)
+	if err != nil {
+		return err
+	}
+	return slf.conn.Publish(slf.broadcastSubject(), data)
+}
+
+// PushGroupMessage 向加入了特定分组的服务器推送跨服消息
+//   - 通过 WithNatsGroups 加入的分组支持 nats 通配符，例如加入 "asia.*" 的服务器将收到推送至 "asia.cn" 的消息
+func (slf *Nats) PushGroupMessage(group string, packet []byte) error {
+	data, err := slf.marshal(generateTraceId(), packet)
 	if err != nil {
 		return err
 	}
-	return slf.conn.Publish(fmt.Sprintf("%s_%d", slf.subject, serverId), data)
+	return slf.conn.Publish(slf.groupSubject(group), data)
 }
 
 func (slf *Nats) Release() {
 	slf.conn.Close()
 }
+
+// marshal 将数据包以本服的身份封装为跨服消息
+func (slf *Nats) marshal(traceId string, packet []byte) ([]byte, error) {
+	message := slf.messagePool.Get()
+	defer slf.messagePool.Release(message)
+	message.ServerId = slf.serverId
+	message.Packet = packet
+	message.TraceId = traceId
+	message.SendTime = time.Now().UnixNano()
+	return slf.codec.Marshal(message)
+}
+
+// directSubject 获取特定服务器的主题
+func (slf *Nats) directSubject(serverId int64) string {
+	return fmt.Sprintf("%s_%d", slf.subject, serverId)
+}
+
+// broadcastSubject 获取广播主题
+func (slf *Nats) broadcastSubject() string {
+	return fmt.Sprintf("%s.broadcast", slf.subject)
+}
+
+// groupSubject 获取特定分组的主题
+func (slf *Nats) groupSubject(group string) string {
+	return fmt.Sprintf("%s.group.%s", slf.subject, group)
+}
+
+// generateTraceId 生成随机的追踪id
+func generateTraceId() string {
+	var buf = make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
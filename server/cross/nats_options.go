@@ -1,6 +1,9 @@
 package cross
 
-import "github.com/nats-io/nats.go"
+import (
+	"github.com/nats-io/nats.go"
+	"time"
+)
 
 type NatsOption func(n *Nats)
 
@@ -26,3 +29,31 @@ func WithNatsConn(conn *nats.Conn) NatsOption {
 		n.conn = conn
 	}
 }
+
+// WithNatsCodec 通过特定的序列化方式创建
+//   - 默认为 BinaryCodec，如需与旧版本的跨服消息保持兼容，可使用 JSONCodec
+//   - 同一主题下的所有服务器需要使用相同的序列化方式
+func WithNatsCodec(codec Codec) NatsOption {
+	return func(n *Nats) {
+		if codec != nil {
+			n.codec = codec
+		}
+	}
+}
+
+// WithNatsLatency 通过接收跨服消息时获取传输耗时的方式创建
+//   - 传输耗时为接收时间与发送时间之差，需确保服务器之间的时钟同步
+func WithNatsLatency(handle func(serverId int64, traceId string, latency time.Duration)) NatsOption {
+	return func(n *Nats) {
+		n.latency = handle
+	}
+}
+
+// WithNatsGroups 通过加入特定分组的方式创建
+//   - 加入分组后将能够接收到通过 PushGroupMessage 推送至该分组的跨服消息
+//   - 分组名称支持 nats 通配符，例如 "asia.*" 或 "asia.>"
+func WithNatsGroups(groups ...string) NatsOption {
+	return func(n *Nats) {
+		n.groups = append(n.groups, groups...)
+	}
+}
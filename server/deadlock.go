@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadlockDetectOption 死锁检测可选项
+type DeadlockDetectOption func(detect *deadlockDetect)
+
+// DeadlockDetectTypeThreshold 为特定类型的消息设置独立的死锁检测阈值
+func DeadlockDetectTypeThreshold(messageType MessageType, threshold time.Duration) DeadlockDetectOption {
+	return func(detect *deadlockDetect) {
+		detect.types[messageType] = threshold
+	}
+}
+
+// DeadlockDetectRouteThreshold 为特定路由的数据包消息设置独立的死锁检测阈值
+//   - resolver 用于从数据包中解析出路由，返回 nil 时表示不存在路由，将在每个数据包消息开始处理时调用
+//   - thresholds 为路由对应的阈值，优先级高于 DeadlockDetectTypeThreshold
+func DeadlockDetectRouteThreshold(resolver func(packet []byte) (route any), thresholds map[any]time.Duration) DeadlockDetectOption {
+	return func(detect *deadlockDetect) {
+		detect.resolver = resolver
+		for route, threshold := range thresholds {
+			detect.routes[route] = threshold
+		}
+	}
+}
+
+// DeadlockDetectAllGoroutines 在检测到疑似死锁时同时采集所有协程的堆栈
+func DeadlockDetectAllGoroutines() DeadlockDetectOption {
+	return func(detect *deadlockDetect) {
+		detect.all = true
+	}
+}
+
+// DeadlockReport 疑似死锁报告
+type DeadlockReport struct {
+	MessageType MessageType   // 消息类型
+	Message     string        // 消息的字符串表示
+	Route       any           // 通过 DeadlockDetectRouteThreshold 解析出的路由
+	Threshold   time.Duration // 触发检测的阈值
+	Duration    time.Duration // 消息已执行的时长，当 Finished 为 true 时为消息执行的总时长
+	Finished    bool          // 消息是否已执行完成
+	Stack       string        // 执行消息的协程堆栈
+	AllStacks   string        // 所有协程的堆栈，需要通过 DeadlockDetectAllGoroutines 开启
+}
+
+// newDeadlockDetect 创建死锁检测
+func newDeadlockDetect(threshold time.Duration) *deadlockDetect {
+	return &deadlockDetect{
+		threshold: threshold,
+		types:     map[MessageType]time.Duration{},
+		routes:    map[any]time.Duration{},
+		watches:   map[*deadlockWatch]struct{}{},
+	}
+}
+
+// deadlockDetect 基于单个检测协程的死锁检测
+//   - 所有函数均允许在 nil 上调用，此时将不进行任何检测
+type deadlockDetect struct {
+	srv       *Server                       // 服务器
+	threshold time.Duration                 // 默认阈值
+	types     map[MessageType]time.Duration // 消息类型阈值
+	routes    map[any]time.Duration         // 路由阈值
+	resolver  func(packet []byte) any       // 路由解析器
+	all       bool                          // 是否采集所有协程的堆栈
+	watches   map[*deadlockWatch]struct{}   // 正在执行的消息
+	stop      chan struct{}                 // 停止信号
+	l         sync.Mutex                    // 执行中消息及停止信号锁
+}
+
+// deadlockWatch 正在执行的消息
+type deadlockWatch struct {
+	msg       *Message        // 消息
+	route     any             // 路由
+	goroutine int64           // 执行消息的协程ID
+	start     time.Time       // 开始执行的时间
+	threshold time.Duration   // 阈值
+	report    *DeadlockReport // 疑似死锁报告，未报告时为 nil
+}
+
+// goroutine 获取当前协程的ID，未开启死锁检测时返回 0
+//   - 获取协程ID需要读取协程堆栈，分发消息的协程应在启动时获取一次并在每次调用 begin 时传入
+func (slf *deadlockDetect) goroutine() int64 {
+	if slf == nil {
+		return 0
+	}
+	return goroutineID()
+}
+
+// begin 开始检测消息的执行，goroutine 为执行消息的协程ID
+func (slf *deadlockDetect) begin(msg *Message, goroutine int64) *deadlockWatch {
+	if slf == nil {
+		return nil
+	}
+	watch := &deadlockWatch{msg: msg, goroutine: goroutine, start: time.Now(), threshold: slf.threshold}
+	if threshold, exist := slf.types[msg.t]; exist {
+		watch.threshold = threshold
+	}
+	if slf.resolver != nil && msg.t == MessageTypePacket {
+		if _, packet := msg.GetPacketMessageAttrs(); packet != nil {
+			if watch.route = slf.resolver(packet); watch.route != nil {
+				if threshold, exist := slf.routes[watch.route]; exist {
+					watch.threshold = threshold
+				}
+			}
+		}
+	}
+	slf.l.Lock()
+	slf.watches[watch] = struct{}{}
+	slf.l.Unlock()
+	return watch
+}
+
+// end 结束检测消息的执行，当该消息曾被报告疑似死锁时，将报告消息执行的总时长
+func (slf *deadlockDetect) end(watch *deadlockWatch) {
+	if slf == nil || watch == nil {
+		return
+	}
+	slf.l.Lock()
+	delete(slf.watches, watch)
+	report := watch.report
+	slf.l.Unlock()
+	if report == nil {
+		return
+	}
+	finished := *report
+	finished.Finished = true
+	finished.Duration = time.Since(watch.start)
+	finished.Stack, finished.AllStacks = "", ""
+	log.Warn("Server", log.String("MessageType", messageNames[finished.MessageType]), log.String("SuspectedDeadlock", "finished"),
+		log.String("message", finished.Message), log.Duration("duration", finished.Duration))
+	slf.srv.OnSuspectedDeadlockEvent(&finished)
+}
+
+// check 检查是否存在超过阈值仍未执行完成的消息
+func (slf *deadlockDetect) check() {
+	var reports []*DeadlockReport
+	var stacks string
+	slf.l.Lock()
+	for watch := range slf.watches {
+		cost := time.Since(watch.start)
+		if watch.report != nil || watch.threshold <= 0 || cost < watch.threshold {
+			continue
+		}
+		if len(stacks) == 0 {
+			stacks = allStacks()
+		}
+		watch.report = &DeadlockReport{
+			MessageType: watch.msg.t,
+			Message:     watch.msg.String(),
+			Route:       watch.route,
+			Threshold:   watch.threshold,
+			Duration:    cost,
+			Stack:       goroutineStack(stacks, watch.goroutine),
+		}
+		if slf.all {
+			watch.report.AllStacks = stacks
+		}
+		reports = append(reports, watch.report)
+	}
+	slf.l.Unlock()
+
+	for _, report := range reports {
+		log.Warn("Server", log.String("MessageType", messageNames[report.MessageType]), log.String("SuspectedDeadlock", report.Message),
+			log.Any("route", report.Route), log.Duration("threshold", report.Threshold), log.Duration("duration", report.Duration), log.String("stack", report.Stack))
+		slf.srv.OnSuspectedDeadlockEvent(report)
+	}
+}
+
+// interval 获取检测间隔
+func (slf *deadlockDetect) interval() time.Duration {
+	var interval = slf.threshold
+	for _, threshold := range slf.types {
+		if threshold > 0 && threshold < interval {
+			interval = threshold
+		}
+	}
+	for _, threshold := range slf.routes {
+		if threshold > 0 && threshold < interval {
+			interval = threshold
+		}
+	}
+	interval /= 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// runDeadlockDetect 开始运行死锁检测协程
+func (slf *Server) runDeadlockDetect() {
+	if slf.deadlockDetect == nil {
+		return
+	}
+	var stop = make(chan struct{})
+	slf.deadlockDetect.srv = slf
+	slf.deadlockDetect.l.Lock()
+	slf.deadlockDetect.stop = stop
+	slf.deadlockDetect.l.Unlock()
+	go func(detect *deadlockDetect, stop <-chan struct{}) {
+		ticker := time.NewTicker(detect.interval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				detect.check()
+			case <-stop:
+				return
+			}
+		}
+	}(slf.deadlockDetect, stop)
+}
+
+// stopDeadlockDetect 停止死锁检测协程
+func (slf *Server) stopDeadlockDetect() {
+	if slf.deadlockDetect == nil {
+		return
+	}
+	slf.deadlockDetect.l.Lock()
+	stop := slf.deadlockDetect.stop
+	slf.deadlockDetect.stop = nil
+	slf.deadlockDetect.l.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+}
+
+// goroutineID 获取当前协程的ID
+func goroutineID() int64 {
+	var buf [64]byte
+	n := goruntime.Stack(buf[:], false)
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseInt(string(fields[1]), 10, 64)
+	return id
+}
+
+// allStacks 获取所有协程的堆栈
+func allStacks() string {
+	buf := make([]byte, 1024*64)
+	for {
+		n := goruntime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// goroutineStack 从所有协程的堆栈中获取特定协程的堆栈
+func goroutineStack(stacks string, id int64) string {
+	prefix := fmt.Sprintf("goroutine %d [", id)
+	for _, stack := range strings.Split(stacks, "\n\n") {
+		if strings.HasPrefix(stack, prefix) {
+			return stack
+		}
+	}
+	return ""
+}
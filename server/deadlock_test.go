@@ -0,0 +1,65 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDeadlockDetect(t *testing.T) {
+	var reports = make(chan *server.DeadlockReport, 4)
+	var started = make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithDeadlockDetect(time.Minute,
+		server.DeadlockDetectTypeThreshold(server.MessageTypeSystem, 50*time.Millisecond),
+		server.DeadlockDetectRouteThreshold(func(packet []byte) any {
+			return string(packet)
+		}, map[any]time.Duration{"slow": 50 * time.Millisecond}),
+	))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	srv.RegSuspectedDeadlockEvent(func(srv *server.Server, report *server.DeadlockReport) {
+		reports <- report
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	var next = func() *server.DeadlockReport {
+		select {
+		case report := <-reports:
+			return report
+		case <-time.After(5 * time.Second):
+			t.Fatal("report timeout")
+		}
+		return nil
+	}
+
+	server.PushSystemMessage(srv, func() {
+		time.Sleep(200 * time.Millisecond)
+	}, "sleep")
+	if report := next(); report.Finished || report.MessageType != server.MessageTypeSystem || !strings.Contains(report.Stack, "TestWithDeadlockDetect") {
+		t.Fatal(report)
+	}
+	if report := next(); !report.Finished || report.Duration < 200*time.Millisecond {
+		t.Fatal(report)
+	}
+
+	conn := server.NewEmptyConn(srv)
+	server.PushPacketMessage(srv, conn, 0, []byte("fast"))
+	server.PushPacketMessage(srv, conn, 0, []byte("slow"))
+	if report := next(); report.Finished || report.Route != "slow" {
+		t.Fatal(report)
+	}
+	if report := next(); !report.Finished || report.Route != "slow" {
+		t.Fatal(report)
+	}
+}
@@ -0,0 +1,71 @@
+package dispatcher
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 消息体的编解码方式
+type Codec interface {
+	// Marshal 编码
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 解码
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec 基于 JSON 的编解码方式
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtobufCodec 基于 protobuf 的编解码方式，消息需实现 proto.Message 接口
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Header 数据包头部的读写方式
+type Header interface {
+	// Unpack 从数据包中读取消息 ID 及消息体
+	Unpack(packet []byte) (id uint32, body []byte, err error)
+	// Pack 将消息 ID 及消息体组合为数据包
+	Pack(id uint32, body []byte) []byte
+}
+
+// Uint32Header 以大端序 4 字节作为消息 ID 的数据包头部
+//   - | id(4) | body |
+type Uint32Header struct{}
+
+func (Uint32Header) Unpack(packet []byte) (id uint32, body []byte, err error) {
+	if len(packet) < 4 {
+		return 0, nil, ErrPacketTooShort
+	}
+	return binary.BigEndian.Uint32(packet), packet[4:], nil
+}
+
+func (Uint32Header) Pack(id uint32, body []byte) []byte {
+	packet := make([]byte, 4+len(body))
+	binary.BigEndian.PutUint32(packet, id)
+	copy(packet[4:], body)
+	return packet
+}
@@ -0,0 +1,138 @@
+package dispatcher
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/router"
+	"google.golang.org/protobuf/proto"
+	"math"
+	"reflect"
+)
+
+// Middleware 分发器中间件
+//   - msg 为解码后的消息，调用 next 将继续执行后续中间件及处理函数，不调用时将中断本次分发
+type Middleware func(conn *server.Conn, id uint32, msg any, next func())
+
+// decoder 将消息体解码为已注册的消息类型，并返回调用强类型处理函数的入口
+type decoder func(body []byte) (msg any, invoke func(conn *server.Conn), err error)
+
+// NewDispatcher 创建消息分发器
+func NewDispatcher(options ...Option) *Dispatcher {
+	dispatcher := &Dispatcher{
+		events: newEvents(),
+		header: Uint32Header{},
+		codec:  JSONCodec{},
+		router: router.NewLevel1Router[uint32, decoder](),
+		types:  make(map[reflect.Type]uint32),
+	}
+	for _, option := range options {
+		option(dispatcher)
+	}
+	return dispatcher
+}
+
+// Dispatcher 消息分发器
+type Dispatcher struct {
+	*events
+	header      Header                                // 数据包头部
+	codec       Codec                                 // 非 proto.Message 消息的编解码方式
+	router      *router.Level1Router[uint32, decoder] // 消息路由
+	types       map[reflect.Type]uint32               // 消息类型对应的消息 ID
+	middlewares []Middleware                          // 中间件
+}
+
+// Register 注册消息 ID 对应的消息类型及强类型处理函数
+//   - 当 *T 实现了 proto.Message 时将使用 ProtobufCodec 进行解码，否则将使用分发器的 Codec 进行解码
+//   - 重复注册同一消息 ID 将会发生 panic
+func Register[T any](dispatcher *Dispatcher, id uint32, handler func(conn *server.Conn, msg *T)) {
+	codec := dispatcher.codecOf(new(T))
+	dispatcher.router.Route(id, func(body []byte) (any, func(conn *server.Conn), error) {
+		var msg = new(T)
+		if err := codec.Unmarshal(body, msg); err != nil {
+			return nil, nil, err
+		}
+		return msg, func(conn *server.Conn) {
+			handler(conn, msg)
+		}, nil
+	})
+	dispatcher.types[reflect.TypeOf(new(T))] = id
+}
+
+// Use 添加中间件，中间件将按照添加顺序执行
+func (slf *Dispatcher) Use(middlewares ...Middleware) {
+	slf.middlewares = append(slf.middlewares, middlewares...)
+}
+
+// Bind 将分发器绑定到服务器，服务器接收到的数据包将交由分发器进行分发
+func (slf *Dispatcher) Bind(srv *server.Server) {
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		slf.Dispatch(conn, packet)
+	}, math.MinInt)
+}
+
+// Dispatch 对数据包进行分发
+func (slf *Dispatcher) Dispatch(conn *server.Conn, packet []byte) {
+	id, body, err := slf.header.Unpack(packet)
+	if err != nil {
+		slf.OnDecodeErrorEvent(slf, conn, 0, packet, err)
+		return
+	}
+	decode := slf.router.Match(id)
+	if decode == nil {
+		slf.OnUnknownMessageEvent(slf, conn, id, body)
+		return
+	}
+	msg, invoke, err := decode(body)
+	if err != nil {
+		slf.OnDecodeErrorEvent(slf, conn, id, packet, err)
+		return
+	}
+
+	var index int
+	var next func()
+	next = func() {
+		if index < len(slf.middlewares) {
+			middleware := slf.middlewares[index]
+			index++
+			middleware(conn, id, msg, next)
+			return
+		}
+		invoke(conn)
+	}
+	next()
+}
+
+// Marshal 将已注册类型的消息编码为数据包
+func (slf *Dispatcher) Marshal(msg any) ([]byte, error) {
+	id, exist := slf.types[reflect.TypeOf(msg)]
+	if !exist {
+		return nil, ErrUnregisteredMessage
+	}
+	return slf.MarshalWithID(id, msg)
+}
+
+// MarshalWithID 将消息以特定的消息 ID 编码为数据包
+func (slf *Dispatcher) MarshalWithID(id uint32, msg any) ([]byte, error) {
+	body, err := slf.codecOf(msg).Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return slf.header.Pack(id, body), nil
+}
+
+// Write 将已注册类型的消息编码后写入连接
+func (slf *Dispatcher) Write(conn *server.Conn, msg any, callback ...func(err error)) error {
+	packet, err := slf.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	conn.Write(packet, callback...)
+	return nil
+}
+
+// codecOf 获取消息适用的编解码方式
+func (slf *Dispatcher) codecOf(msg any) Codec {
+	if _, ok := msg.(proto.Message); ok {
+		return ProtobufCodec{}
+	}
+	return slf.codec
+}
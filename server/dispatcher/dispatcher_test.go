@@ -0,0 +1,57 @@
+package dispatcher_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/dispatcher"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+type Login struct {
+	Account string `json:"account"`
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	var (
+		d       = dispatcher.NewDispatcher()
+		conn    = server.NewEmptyConn(server.New(server.NetworkNone))
+		trace   []string
+		unknown uint32
+	)
+	dispatcher.Register[Login](d, 1, func(conn *server.Conn, msg *Login) {
+		trace = append(trace, "login:"+msg.Account)
+	})
+	dispatcher.Register[wrapperspb.StringValue](d, 2, func(conn *server.Conn, msg *wrapperspb.StringValue) {
+		trace = append(trace, "proto:"+msg.GetValue())
+	})
+	d.Use(func(conn *server.Conn, id uint32, msg any, next func()) {
+		trace = append(trace, "before")
+		next()
+		trace = append(trace, "after")
+	})
+	d.RegUnknownMessageEvent(func(dispatcher *dispatcher.Dispatcher, conn *server.Conn, id uint32, body []byte) {
+		unknown = id
+	})
+
+	for _, msg := range []any{&Login{Account: "minotaur"}, wrapperspb.String("hello")} {
+		packet, err := d.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Dispatch(conn, packet)
+	}
+	d.Dispatch(conn, dispatcher.Uint32Header{}.Pack(3, nil))
+
+	expect := []string{"before", "login:minotaur", "after", "before", "proto:hello", "after"}
+	if len(trace) != len(expect) {
+		t.Fatal(trace)
+	}
+	for i := range expect {
+		if trace[i] != expect[i] {
+			t.Fatal(trace)
+		}
+	}
+	if unknown != 3 {
+		t.Fatal(unknown)
+	}
+}
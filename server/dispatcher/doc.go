@@ -0,0 +1,4 @@
+// Package dispatcher 提供了基于消息 ID 的强类型消息分发器
+//   - 分发器将从数据包头部读取消息 ID，查找已注册的消息类型并自动解码，随后调用强类型的处理函数
+//   - 支持 protobuf（proto.Message）及 JSON 结构体，支持中间件及未知消息事件
+package dispatcher
@@ -0,0 +1,9 @@
+package dispatcher
+
+import "errors"
+
+var (
+	ErrPacketTooShort      = errors.New("the packet is too short to contain a message id")
+	ErrNotProtoMessage     = errors.New("the message does not implement proto.Message")
+	ErrUnregisteredMessage = errors.New("the message type is not registered")
+)
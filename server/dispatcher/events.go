@@ -0,0 +1,48 @@
+package dispatcher
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/slice"
+)
+
+type (
+	UnknownMessageEventHandle func(dispatcher *Dispatcher, conn *server.Conn, id uint32, body []byte)
+	DecodeErrorEventHandle    func(dispatcher *Dispatcher, conn *server.Conn, id uint32, packet []byte, err error)
+)
+
+func newEvents() *events {
+	return &events{
+		unknownMessageEventHandles: slice.NewPriority[UnknownMessageEventHandle](),
+		decodeErrorEventHandles:    slice.NewPriority[DecodeErrorEventHandle](),
+	}
+}
+
+type events struct {
+	unknownMessageEventHandles *slice.Priority[UnknownMessageEventHandle]
+	decodeErrorEventHandles    *slice.Priority[DecodeErrorEventHandle]
+}
+
+// RegUnknownMessageEvent 在接收到未注册的消息 ID 时将立即执行被注册的事件处理函数
+func (slf *events) RegUnknownMessageEvent(handle UnknownMessageEventHandle, priority ...int) {
+	slf.unknownMessageEventHandles.Append(handle, slice.GetValue(priority, 0))
+}
+
+func (slf *events) OnUnknownMessageEvent(dispatcher *Dispatcher, conn *server.Conn, id uint32, body []byte) {
+	slf.unknownMessageEventHandles.RangeValue(func(index int, value UnknownMessageEventHandle) bool {
+		value(dispatcher, conn, id, body)
+		return true
+	})
+}
+
+// RegDecodeErrorEvent 在数据包头部读取失败或消息解码失败时将立即执行被注册的事件处理函数
+//   - 当数据包头部读取失败时，id 为 0
+func (slf *events) RegDecodeErrorEvent(handle DecodeErrorEventHandle, priority ...int) {
+	slf.decodeErrorEventHandles.Append(handle, slice.GetValue(priority, 0))
+}
+
+func (slf *events) OnDecodeErrorEvent(dispatcher *Dispatcher, conn *server.Conn, id uint32, packet []byte, err error) {
+	slf.decodeErrorEventHandles.RangeValue(func(index int, value DecodeErrorEventHandle) bool {
+		value(dispatcher, conn, id, packet, err)
+		return true
+	})
+}
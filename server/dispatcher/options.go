@@ -0,0 +1,25 @@
+package dispatcher
+
+// Option 分发器选项
+type Option func(dispatcher *Dispatcher)
+
+// WithHeader 设置数据包头部的读写方式
+//   - 默认为 Uint32Header
+func WithHeader(header Header) Option {
+	return func(dispatcher *Dispatcher) {
+		if header != nil {
+			dispatcher.header = header
+		}
+	}
+}
+
+// WithCodec 设置非 proto.Message 类型消息的编解码方式
+//   - 默认为 JSONCodec
+//   - 实现了 proto.Message 的消息类型始终使用 ProtobufCodec
+func WithCodec(codec Codec) Option {
+	return func(dispatcher *Dispatcher) {
+		if codec != nil {
+			dispatcher.codec = codec
+		}
+	}
+}
@@ -7,8 +7,26 @@ var (
 	ErrCanNotSupportNetwork        = errors.New("can not support network")
 	ErrNetworkOnlySupportHttp      = errors.New("the current network mode is not compatible with HttpRouter, only NetworkHttp is supported")
 	ErrNetworkOnlySupportGRPC      = errors.New("the current network mode is not compatible with RegGrpcServer, only NetworkGRPC is supported")
+	ErrNetworkOnlySupportWebsocket = errors.New("the current network mode is not compatible with WebsocketServeMux, only NetworkWebsocket is supported")
 	ErrNetworkIncompatibleHttp     = errors.New("the current network mode is not compatible with NetworkHttp")
 	ErrWebsocketIllegalMessageType = errors.New("illegal message type")
 	ErrNoSupportCross              = errors.New("the server does not support GetID or PushCrossMessage, please use the WithCross option to create the server")
+	ErrNoSupportShunt              = errors.New("the server does not support ShuntRebind, please use the WithShunt option to create the server")
 	ErrNoSupportTicker             = errors.New("the server does not support Ticker, please use the WithTicker option to create the server")
+	ErrCodecIllegalHeaderSize      = errors.New("the length field header size of codec only supports 1, 2, 4 or 8")
+	ErrPacketTooLarge              = errors.New("the packet length exceeds the limit")
+	ErrConnectionRateLimited       = errors.New("the connection receives packets too frequently")
+	ErrConnectionLimitPerIP        = errors.New("the number of connections from the ip exceeds the limit")
+	ErrConnectionIdleTimeout       = errors.New("the connection has not received any data for too long")
+	ErrConnectionWriteQueueFull    = errors.New("the write queue of the connection is full")
+	ErrServerMaintenance           = errors.New("the server is under maintenance or shutting down")
+	ErrHotRestartNotSupported      = errors.New("hot restart is only supported on linux")
+	ErrHotRestartNotEnabled        = errors.New("hot restart is not enabled, please use the WithHotRestart option to create the server")
+	ErrHotRestartNotSupportNetwork = errors.New("hot restart does not support the network")
+	ErrHotRestartChildFailed       = errors.New("the hot restart child process failed to start")
+	ErrHandshakeTimeout            = errors.New("the connection did not complete the handshake in time")
+	ErrHandshakeFailed             = errors.New("the connection did not pass the handshake within the allowed packets")
+	ErrSecureHandshakeTimeout      = errors.New("the connection did not complete the secure key exchange in time")
+	ErrUDPSessionExpired           = errors.New("the udp session expired without receiving any datagram")
+	ErrQUICCertificateRequired     = errors.New("quic requires a certificate in RunModeProd, please use the WithTLS option to create the server")
 )
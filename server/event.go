@@ -20,6 +20,7 @@ type ConnectionReceivePacketEventHandle func(srv *Server, conn *Conn, packet []b
 type ConnectionOpenedEventHandle func(srv *Server, conn *Conn)
 type ConnectionClosedEventHandle func(srv *Server, conn *Conn, err any)
 type ReceiveCrossPacketEventHandle func(srv *Server, senderServerId int64, packet []byte)
+type ReceiveCrossRequestEventHandle func(srv *Server, senderServerId int64, packet []byte, reply func(packet []byte) error)
 type MessageErrorEventHandle func(srv *Server, message *Message, err error)
 type MessageLowExecEventHandle func(srv *Server, message *Message, cost time.Duration)
 type ConsoleCommandEventHandle func(srv *Server)
@@ -30,6 +31,10 @@ type ShuntChannelClosedEventHandle func(srv *Server, guid int64)
 type ConnectionPacketPreprocessEventHandle func(srv *Server, conn *Conn, packet []byte, abort func(), usePacket func(newPacket []byte))
 type MessageExecBeforeEventHandle func(srv *Server, message *Message) bool
 type MessageReadyEventHandle func(srv *Server)
+type ConnectionRateLimitedEventHandle func(srv *Server, ip string, conn *Conn, action RateLimitAction, err error)
+type ConnectionSlowEventHandle func(srv *Server, conn *Conn, policy WriteQueuePolicy, pendingPackets int, pendingBytes int64)
+type ConnectionHandshakeFailedEventHandle func(srv *Server, conn *Conn, err error)
+type SuspectedDeadlockEventHandle func(srv *Server, report *DeadlockReport)
 
 func newEvent(srv *Server) *event {
 	return &event{
@@ -41,6 +46,7 @@ func newEvent(srv *Server) *event {
 		connectionOpenedEventHandles:           slice.NewPriority[ConnectionOpenedEventHandle](),
 		connectionClosedEventHandles:           slice.NewPriority[ConnectionClosedEventHandle](),
 		receiveCrossPacketEventHandles:         slice.NewPriority[ReceiveCrossPacketEventHandle](),
+		receiveCrossRequestEventHandles:        slice.NewPriority[ReceiveCrossRequestEventHandle](),
 		messageErrorEventHandles:               slice.NewPriority[MessageErrorEventHandle](),
 		messageLowExecEventHandles:             slice.NewPriority[MessageLowExecEventHandle](),
 		connectionOpenedAfterEventHandles:      slice.NewPriority[ConnectionOpenedAfterEventHandle](),
@@ -50,6 +56,10 @@ func newEvent(srv *Server) *event {
 		connectionPacketPreprocessEventHandles: slice.NewPriority[ConnectionPacketPreprocessEventHandle](),
 		messageExecBeforeEventHandles:          slice.NewPriority[MessageExecBeforeEventHandle](),
 		messageReadyEventHandles:               slice.NewPriority[MessageReadyEventHandle](),
+		connectionRateLimitedEventHandles:      slice.NewPriority[ConnectionRateLimitedEventHandle](),
+		connectionSlowEventHandles:             slice.NewPriority[ConnectionSlowEventHandle](),
+		connectionHandshakeFailedEventHandles:  slice.NewPriority[ConnectionHandshakeFailedEventHandle](),
+		suspectedDeadlockEventHandles:          slice.NewPriority[SuspectedDeadlockEventHandle](),
 	}
 }
 
@@ -62,6 +72,7 @@ type event struct {
 	connectionOpenedEventHandles           *slice.Priority[ConnectionOpenedEventHandle]
 	connectionClosedEventHandles           *slice.Priority[ConnectionClosedEventHandle]
 	receiveCrossPacketEventHandles         *slice.Priority[ReceiveCrossPacketEventHandle]
+	receiveCrossRequestEventHandles        *slice.Priority[ReceiveCrossRequestEventHandle]
 	messageErrorEventHandles               *slice.Priority[MessageErrorEventHandle]
 	messageLowExecEventHandles             *slice.Priority[MessageLowExecEventHandle]
 	connectionOpenedAfterEventHandles      *slice.Priority[ConnectionOpenedAfterEventHandle]
@@ -71,6 +82,10 @@ type event struct {
 	connectionPacketPreprocessEventHandles *slice.Priority[ConnectionPacketPreprocessEventHandle]
 	messageExecBeforeEventHandles          *slice.Priority[MessageExecBeforeEventHandle]
 	messageReadyEventHandles               *slice.Priority[MessageReadyEventHandle]
+	connectionRateLimitedEventHandles      *slice.Priority[ConnectionRateLimitedEventHandle]
+	connectionSlowEventHandles             *slice.Priority[ConnectionSlowEventHandle]
+	connectionHandshakeFailedEventHandles  *slice.Priority[ConnectionHandshakeFailedEventHandle]
+	suspectedDeadlockEventHandles          *slice.Priority[SuspectedDeadlockEventHandle]
 
 	consoleCommandEventHandles        map[string]*slice.Priority[ConsoleCommandEventHandle]
 	consoleCommandEventHandleInitOnce sync.Once
@@ -92,6 +107,8 @@ func (slf *event) OnStopEvent() {
 // RegConsoleCommandEvent 控制台收到指令时将立即执行被注册的事件处理函数
 //   - 默认将注册 "exit", "quit", "close", "shutdown", "EXIT", "QUIT", "CLOSE", "SHUTDOWN" 指令作为关闭服务器的指令
 //   - 可通过注册默认指令进行默认行为的覆盖
+//
+// Deprecated: 该函数仅支持在标准输入为终端时读取不带参数的指令，推荐使用 admin 包提供的管理控制台，其支持参数、标志、帮助信息以及标准输入、Unix 套接字、HTTP 等执行方式
 func (slf *event) RegConsoleCommandEvent(command string, handle ConsoleCommandEventHandle, priority ...int) {
 	fd := int(os.Stdin.Fd())
 	if !terminal.IsTerminal(fd) {
@@ -183,6 +200,8 @@ func (slf *event) RegConnectionClosedEvent(handle ConnectionClosedEventHandle, p
 }
 
 func (slf *event) OnConnectionClosedEvent(conn *Conn, err any) {
+	slf.metrics.connection(false)
+	slf.recorder.record(conn, PacketRecordClosed, 0, nil)
 	PushSystemMessage(slf.Server, func() {
 		slf.Server.online.Delete(conn.GetID())
 		slf.connectionClosedEventHandles.RangeValue(func(index int, value ConnectionClosedEventHandle) bool {
@@ -202,6 +221,8 @@ func (slf *event) RegConnectionOpenedEvent(handle ConnectionOpenedEventHandle, p
 }
 
 func (slf *event) OnConnectionOpenedEvent(conn *Conn) {
+	slf.metrics.connection(true)
+	slf.recorder.record(conn, PacketRecordOpened, 0, nil)
 	PushSystemMessage(slf.Server, func() {
 		slf.Server.online.Set(conn.GetID(), conn)
 		slf.connectionOpenedEventHandles.RangeValue(func(index int, value ConnectionOpenedEventHandle) bool {
@@ -240,6 +261,28 @@ func (slf *event) OnReceiveCrossPacketEvent(serverId int64, packet []byte) {
 	})
 }
 
+// RegReceiveCrossRequestEvent 在接收到跨服请求时将立即执行被注册的事件处理函数
+//   - 处理函数应通过 reply 函数回复请求方，请求方将在超时时间内等待回复
+//   - 当存在多个处理函数时，仅第一次回复有效
+func (slf *event) RegReceiveCrossRequestEvent(handle ReceiveCrossRequestEventHandle, priority ...int) {
+	slf.receiveCrossRequestEventHandles.Append(handle, slice.GetValue(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handle", reflect.TypeOf(handle).String()))
+}
+
+func (slf *event) OnReceiveCrossRequestEvent(serverId int64, packet []byte, reply func(packet []byte) error) {
+	var once sync.Once
+	var replyOnce = func(packet []byte) (err error) {
+		once.Do(func() {
+			err = reply(packet)
+		})
+		return err
+	}
+	slf.receiveCrossRequestEventHandles.RangeValue(func(index int, value ReceiveCrossRequestEventHandle) bool {
+		value(slf.Server, serverId, packet, replyOnce)
+		return true
+	})
+}
+
 // RegMessageErrorEvent 在处理消息发生错误时将立即执行被注册的事件处理函数
 func (slf *event) RegMessageErrorEvent(handle MessageErrorEventHandle, priority ...int) {
 	slf.messageErrorEventHandles.Append(handle, slice.GetValue(priority, 0))
@@ -415,6 +458,77 @@ func (slf *event) OnMessageReadyEvent() {
 	})
 }
 
+// RegConnectionRateLimitedEvent 在连接触发限流时将立即执行被注册的事件处理函数
+//   - 数据包超出限流时，conn 为触发限流的连接，err 为 ErrConnectionRateLimited，同一连接每秒最多触发一次
+//   - 连接数超出单个 IP 的限制时，conn 为 nil，err 为 ErrConnectionLimitPerIP
+//
+// 适用于记录或封禁异常客户端等场景
+func (slf *event) RegConnectionRateLimitedEvent(handle ConnectionRateLimitedEventHandle, priority ...int) {
+	slf.connectionRateLimitedEventHandles.Append(handle, slice.GetValue(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handle", reflect.TypeOf(handle).String()))
+}
+
+func (slf *event) OnConnectionRateLimitedEvent(ip string, conn *Conn, action RateLimitAction, err error) {
+	PushSystemMessage(slf.Server, func() {
+		slf.connectionRateLimitedEventHandles.RangeValue(func(index int, value ConnectionRateLimitedEventHandle) bool {
+			value(slf.Server, ip, conn, action, err)
+			return true
+		})
+	}, "ConnectionRateLimitedEvent")
+}
+
+// RegConnectionSlowEvent 在连接写入队列已满时将立即执行被注册的事件处理函数
+//   - policy 为写入队列已满时所执行的策略，pendingPackets 和 pendingBytes 为触发时写入队列中尚未写入的数据包数量及字节数
+//   - 同一连接每秒最多触发一次
+//
+// 适用于主动断开无法及时读取数据的缓慢连接等场景
+func (slf *event) RegConnectionSlowEvent(handle ConnectionSlowEventHandle, priority ...int) {
+	slf.connectionSlowEventHandles.Append(handle, slice.GetValue(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handle", reflect.TypeOf(handle).String()))
+}
+
+func (slf *event) OnConnectionSlowEvent(conn *Conn, policy WriteQueuePolicy, pendingPackets int, pendingBytes int64) {
+	PushSystemMessage(slf.Server, func() {
+		slf.connectionSlowEventHandles.RangeValue(func(index int, value ConnectionSlowEventHandle) bool {
+			value(slf.Server, conn, policy, pendingPackets, pendingBytes)
+			return true
+		})
+	}, "ConnectionSlowEvent")
+}
+
+// RegConnectionHandshakeFailedEvent 在连接握手失败并被关闭后将立即执行被注册的事件处理函数
+//   - 握手失败的连接不会触发 ConnectionOpenedEvent 及 ConnectionClosedEvent
+//   - err 为握手处理函数返回的错误、ErrHandshakeTimeout、ErrHandshakeFailed 或握手期间连接断开的原因
+func (slf *event) RegConnectionHandshakeFailedEvent(handle ConnectionHandshakeFailedEventHandle, priority ...int) {
+	slf.connectionHandshakeFailedEventHandles.Append(handle, slice.GetValue(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handle", reflect.TypeOf(handle).String()))
+}
+
+func (slf *event) OnConnectionHandshakeFailedEvent(conn *Conn, err error) {
+	PushSystemMessage(slf.Server, func() {
+		slf.connectionHandshakeFailedEventHandles.RangeValue(func(index int, value ConnectionHandshakeFailedEventHandle) bool {
+			value(slf.Server, conn, err)
+			return true
+		})
+	}, "ConnectionHandshakeFailedEvent")
+}
+
+// RegSuspectedDeadlockEvent 在检测到疑似死锁的消息及该消息最终执行完成时将立即执行被注册的事件处理函数
+//   - 需要通过 WithDeadlockDetect 开启死锁检测
+//   - 由于消息循环可能已被阻塞，事件处理函数将在死锁检测协程或执行该消息的协程中直接执行，而不是在消息循环中执行，需要注意并发安全
+//   - 可通过 report.Finished 区分疑似死锁及执行完成的报告
+func (slf *event) RegSuspectedDeadlockEvent(handle SuspectedDeadlockEventHandle, priority ...int) {
+	slf.suspectedDeadlockEventHandles.Append(handle, slice.GetValue(priority, 0))
+	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handle", reflect.TypeOf(handle).String()))
+}
+
+func (slf *event) OnSuspectedDeadlockEvent(report *DeadlockReport) {
+	slf.suspectedDeadlockEventHandles.RangeValue(func(index int, value SuspectedDeadlockEventHandle) bool {
+		value(slf.Server, report)
+		return true
+	})
+}
+
 func (slf *event) check() {
 	switch slf.network {
 	case NetworkHttp, NetworkGRPC, NetworkNone:
@@ -428,4 +542,8 @@ func (slf *event) check() {
 		log.Warn("Server", log.String("ReceiveCrossPacketEvent", "invalid server, not register cross server"))
 	}
 
+	if slf.receiveCrossRequestEventHandles.Len() > 0 && slf.cross == nil {
+		log.Warn("Server", log.String("ReceiveCrossRequestEvent", "invalid server, not register cross server"))
+	}
+
 }
@@ -1,13 +1,128 @@
 package server
 
 import (
-	"bytes"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/super"
 	"github.com/panjf2000/gnet"
+	"github.com/panjf2000/gnet/pkg/logging"
 	"time"
 )
 
+const (
+	DefaultGNetTickInterval = time.Second // 默认 gnet 定时维护任务的执行间隔
+)
+
+const (
+	GNetLoadBalancingRoundRobin       = gnet.RoundRobin       // 轮询分配连接到事件循环
+	GNetLoadBalancingLeastConnections = gnet.LeastConnections // 分配连接到连接数最少的事件循环
+	GNetLoadBalancingSourceAddrHash   = gnet.SourceAddrHash   // 根据来源地址哈希分配连接到事件循环
+)
+
+// GNetOption gnet 引擎可选项
+type GNetOption func(options *gnet.Options)
+
+// GNetMulticore 设置是否开启多核模式，开启后将根据 CPU 核心数创建事件循环，默认开启
+func GNetMulticore(multicore bool) GNetOption {
+	return func(options *gnet.Options) {
+		options.Multicore = multicore
+	}
+}
+
+// GNetNumEventLoop 设置事件循环的数量，大于 0 时将忽略 GNetMulticore
+func GNetNumEventLoop(n int) GNetOption {
+	return func(options *gnet.Options) {
+		options.NumEventLoop = n
+	}
+}
+
+// GNetLoadBalancing 设置连接分配到事件循环的负载均衡策略，默认为 GNetLoadBalancingRoundRobin
+func GNetLoadBalancing(lb gnet.LoadBalancing) GNetOption {
+	return func(options *gnet.Options) {
+		options.LB = lb
+	}
+}
+
+// GNetReadBufferCap 设置每个连接读取缓冲区的最大容量，默认为 64KB
+func GNetReadBufferCap(cap int) GNetOption {
+	return func(options *gnet.Options) {
+		options.ReadBufferCap = cap
+	}
+}
+
+// GNetReusePort 设置是否开启 SO_REUSEPORT
+func GNetReusePort(reusePort bool) GNetOption {
+	return func(options *gnet.Options) {
+		options.ReusePort = reusePort
+	}
+}
+
+// GNetReuseAddr 设置是否开启 SO_REUSEADDR
+func GNetReuseAddr(reuseAddr bool) GNetOption {
+	return func(options *gnet.Options) {
+		options.ReuseAddr = reuseAddr
+	}
+}
+
+// GNetTCPKeepAlive 设置 TCP 连接的保活间隔，为 0 时不开启
+func GNetTCPKeepAlive(keepAlive time.Duration) GNetOption {
+	return func(options *gnet.Options) {
+		options.TCPKeepAlive = keepAlive
+	}
+}
+
+// GNetTCPNoDelay 设置是否开启 TCP_NODELAY，默认开启
+func GNetTCPNoDelay(noDelay bool) GNetOption {
+	return func(options *gnet.Options) {
+		options.TCPNoDelay = super.If(noDelay, gnet.TCPNoDelay, gnet.TCPDelay)
+	}
+}
+
+// GNetSocketBuffer 设置套接字的接收及发送缓冲区大小，小于等于 0 时使用系统默认值
+func GNetSocketBuffer(recv, send int) GNetOption {
+	return func(options *gnet.Options) {
+		options.SocketRecvBuffer = recv
+		options.SocketSendBuffer = send
+	}
+}
+
+// GNetLockOSThread 设置是否将事件循环锁定到系统线程
+func GNetLockOSThread(lock bool) GNetOption {
+	return func(options *gnet.Options) {
+		options.LockOSThread = lock
+	}
+}
+
 type gNet struct {
 	*Server
+	udp udpSessions // 虚拟 UDP 会话
+}
+
+// options 获取 gnet 引擎可选项
+func (slf *gNet) options() gnet.Options {
+	options := gnet.Options{
+		Multicore: true,
+		Logger:    log.GetLogger(),
+		LogLevel:  super.If(slf.runMode == RunModeProd, logging.ErrorLevel, logging.DebugLevel),
+	}
+	for _, option := range slf.gnetOptions {
+		option(&options)
+	}
+	options.Ticker = true
+	return options
+}
+
+// isGNetNetwork 是否为基于 gnet 的网络类型
+func isGNetNetwork(network Network) bool {
+	switch network {
+	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix:
+		return true
+	}
+	return false
+}
+
+// isGNetServed 服务器是否由 gnet 运行，启用热重启的 Tcp 网络将基于 net.Listener 运行
+func (slf *Server) isGNetServed() bool {
+	return isGNetNetwork(slf.network) && !slf.isListenerTCP()
 }
 
 func (slf *gNet) OnInitComplete(server gnet.Server) (action gnet.Action) {
@@ -19,14 +134,25 @@ func (slf *gNet) OnShutdown(server gnet.Server) {
 }
 
 func (slf *gNet) OnOpened(c gnet.Conn) (out []byte, action gnet.Action) {
+	if packet, refused := slf.refuse(); refused {
+		return packet, gnet.Close
+	}
+	if !slf.acquireIP(ipOf(c.RemoteAddr())) {
+		return nil, gnet.Close
+	}
 	conn := newGNetConn(slf.Server, c)
 	c.SetContext(conn)
-	slf.OnConnectionOpenedEvent(conn)
+	slf.openConn(conn, func() {
+		slf.OnConnectionOpenedEvent(conn)
+	})
 	return
 }
 
 func (slf *gNet) OnClosed(c gnet.Conn, err error) (action gnet.Action) {
-	conn := c.Context().(*Conn)
+	conn, ok := c.Context().(*Conn)
+	if !ok {
+		return
+	}
 	conn.Close(err)
 	return
 }
@@ -40,10 +166,38 @@ func (slf *gNet) AfterWrite(c gnet.Conn, b []byte) {
 }
 
 func (slf *gNet) React(packet []byte, c gnet.Conn) (out []byte, action gnet.Action) {
-	PushPacketMessage(slf.Server, c.Context().(*Conn), 0, bytes.Clone(packet))
+	conn, ok := c.Context().(*Conn)
+	if !ok {
+		if !isUDPNetwork(slf.network) {
+			return nil, gnet.Close
+		}
+		if conn = slf.udpSession(c); conn == nil {
+			return nil, gnet.None
+		}
+	}
+	if err := conn.receive(0, packet); err != nil {
+		log.Error("Server", log.String("network", string(slf.network)), log.String("remote", conn.GetID()), log.Err(err))
+		if conn.isUDPSession() {
+			conn.Close(err)
+			return nil, gnet.None
+		}
+		return nil, gnet.Close
+	}
 	return nil, gnet.None
 }
 
+// Tick 由 gnet 定时调用，用于执行服务器的周期性维护任务
 func (slf *gNet) Tick() (delay time.Duration, action gnet.Action) {
+	delay = DefaultGNetTickInterval
+	if slf.isShutdown.Load() {
+		return
+	}
+	if interval := slf.idleCheckInterval(); interval > 0 {
+		delay = interval
+		PushSystemMessage(slf.Server, slf.checkIdle, "IdleDetect")
+	}
+	if isUDPNetwork(slf.network) {
+		delay = min(delay, slf.expireUDPSessions())
+	}
 	return
 }
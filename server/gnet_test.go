@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithGNetOptions(t *testing.T) {
+	var closed = make(chan any, 1)
+	srv := server.New(server.NetworkTcp,
+		server.WithGNetOptions(
+			server.GNetNumEventLoop(2),
+			server.GNetLoadBalancing(server.GNetLoadBalancingLeastConnections),
+			server.GNetReusePort(true),
+			server.GNetTCPNoDelay(true),
+			server.GNetTCPKeepAlive(time.Minute),
+		),
+		server.WithConnectionIdleTimeout(300*time.Millisecond),
+	)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- err
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9985"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9985")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("hello"))
+	var buf = make([]byte, 5)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = conn.Read(buf); err != nil || string(buf) != "hello" {
+		t.Fatal(string(buf), err)
+	}
+
+	// 空闲连接检测由 gnet 的定时器驱动
+	select {
+	case err := <-closed:
+		if e, ok := err.(error); !ok || !errors.Is(e, server.ErrConnectionIdleTimeout) {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle timeout not triggered")
+	}
+}
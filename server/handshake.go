@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultHandshakeTimeout = 10 * time.Second // 默认握手超时时间
+)
+
+const (
+	handshakePending int32 = iota // 握手中
+	handshakePassed               // 握手成功
+	handshakeFailed               // 握手失败
+)
+
+// HandshakeHandle 握手处理函数
+//   - 返回 pass 为 true 时表示握手成功，连接将被视为打开
+//   - 返回 err 不为 nil 时表示握手失败，连接将被关闭
+type HandshakeHandle func(srv *Server, conn *Conn, packet []byte) (pass bool, err error)
+
+// WebsocketUpgradeAuthenticator Websocket升级前的鉴权函数
+//   - 返回 err 不为 nil 时将拒绝升级，并以 status 作为响应状态码，当 status 为 0 时将使用 http.StatusUnauthorized
+type WebsocketUpgradeAuthenticator func(request *http.Request) (status int, err error)
+
+// handshakeConfig 握手配置
+type handshakeConfig struct {
+	packets int             // 握手阶段允许接收的数据包数量
+	timeout time.Duration   // 握手超时时间
+	handle  HandshakeHandle // 握手处理函数
+}
+
+// handshake 连接的握手状态
+type handshake struct {
+	state     atomic.Int32 // 握手状态
+	remaining int          // 剩余允许接收的数据包数量
+	timer     *time.Timer  // 超时定时器
+	open      func()       // 握手成功后打开连接的函数
+}
+
+// isHandshaking 连接是否处于握手阶段
+func (slf *Conn) isHandshaking() bool {
+	return slf.handshake != nil && slf.handshake.state.Load() == handshakePending
+}
+
+// IsHandshakePassed 连接是否已通过握手，未设置 WithHandshake 时将始终返回 true
+func (slf *Conn) IsHandshakePassed() bool {
+	return slf.handshake == nil || slf.handshake.state.Load() == handshakePassed
+}
+
+// abort 在连接关闭时中止握手，返回连接是否未通过握手
+func (slf *handshake) abort() bool {
+	slf.state.CompareAndSwap(handshakePending, handshakeFailed)
+	slf.timer.Stop()
+	return slf.state.Load() == handshakeFailed
+}
+
+// openConn 打开连接，当服务器设置了握手时，连接将在握手成功后打开
+//   - 当服务器设置了加密传输时，将首先进行密钥交换，握手超时时间包含密钥交换的时间
+func (slf *Server) openConn(conn *Conn, open func()) {
+	if slf.handshakeConfig != nil {
+		hs := &handshake{remaining: slf.handshakeConfig.packets, open: open}
+		hs.timer = time.AfterFunc(slf.handshakeConfig.timeout, func() {
+			if hs.state.CompareAndSwap(handshakePending, handshakeFailed) {
+				conn.Close(ErrHandshakeTimeout)
+			}
+		})
+		conn.handshake = hs
+		open = nil
+	}
+	if slf.secureConfig != nil {
+		slf.openSecure(conn, open)
+		return
+	}
+	if open != nil {
+		open()
+	}
+}
+
+// handshakePacket 处理握手阶段接收到的数据包
+func (slf *Server) handshakePacket(conn *Conn, packet []byte) {
+	hs := conn.handshake
+	hs.remaining--
+	pass, err := slf.handshakeConfig.handle(slf, conn, packet)
+	switch {
+	case err != nil:
+		if hs.state.CompareAndSwap(handshakePending, handshakeFailed) {
+			conn.Close(err)
+		}
+	case pass:
+		if hs.state.CompareAndSwap(handshakePending, handshakePassed) {
+			hs.timer.Stop()
+			hs.open()
+		}
+	case hs.remaining <= 0:
+		if hs.state.CompareAndSwap(handshakePending, handshakeFailed) {
+			conn.Close(ErrHandshakeFailed)
+		}
+	}
+}
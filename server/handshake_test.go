@@ -0,0 +1,106 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithHandshake(t *testing.T) {
+	var errInvalidToken = errors.New("invalid token")
+	var opened = make(chan struct{}, 1)
+	var received = make(chan string, 1)
+	var failed = make(chan error, 2)
+	var closed = make(chan struct{}, 1)
+	srv := server.New(server.NetworkTcp, server.WithHandshake(1, 200*time.Millisecond, func(srv *server.Server, conn *server.Conn, packet []byte) (bool, error) {
+		if string(packet) != "token" {
+			return false, errInvalidToken
+		}
+		return true, nil
+	}))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if !conn.IsHandshakePassed() {
+			t.Error("connection opened before handshake passed")
+		}
+		opened <- struct{}{}
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- string(packet)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- struct{}{}
+	})
+	srv.RegConnectionHandshakeFailedEvent(func(srv *server.Server, conn *server.Conn, err error) {
+		failed <- err
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9989"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9989")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = conn.Write([]byte("token"))
+	select {
+	case <-opened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handshake timeout")
+	}
+	_, _ = conn.Write([]byte("hello"))
+	select {
+	case packet := <-received:
+		if packet != "hello" {
+			t.Fatal(packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive timeout")
+	}
+
+	for _, c := range []struct {
+		packet string
+		err    error
+	}{{"bad", errInvalidToken}, {"", server.ErrHandshakeTimeout}} {
+		conn, err := net.Dial("tcp", "127.0.0.1:9989")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.packet) > 0 {
+			_, _ = conn.Write([]byte(c.packet))
+		}
+		select {
+		case err := <-failed:
+			if !errors.Is(err, c.err) {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("handshake not failed")
+		}
+		_ = conn.Close()
+	}
+	if len(opened) > 0 || len(received) > 0 {
+		t.Fatal("unauthenticated connection opened")
+	}
+
+	// 在服务器停止前等待连接关闭完成
+	_ = conn.Close()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed")
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envHotRestartListeners = "MINOTAUR_HOT_RESTART_LISTENERS" // 子进程继承的侦听器，格式为 key=fd;key=fd
+	envHotRestartReady     = "MINOTAUR_HOT_RESTART_READY"     // 子进程启动完成后用于通知父进程的文件描述符
+)
+
+const (
+	DefaultHotRestartReadyTimeout = 30 * time.Second // 等待子进程启动完成的默认超时时间
+)
+
+var inherited struct {
+	once  sync.Once
+	files map[string]*os.File // 从父进程继承的侦听器
+	ready *os.File            // 用于通知父进程启动完成的文件
+	l     sync.Mutex
+}
+
+// hotRestart 热重启配置
+type hotRestart struct {
+	drain time.Duration // 交接完成后等待已有连接断开的最长时间
+}
+
+// hotRestartSignal 通过 Server.HotRestart 主动触发热重启时使用的信号
+type hotRestartSignal struct{}
+
+func (hotRestartSignal) String() string {
+	return "hot restart"
+}
+
+func (hotRestartSignal) Signal() {}
+
+// isHotRestartSignal 检查信号是否为热重启信号
+func isHotRestartSignal(sig os.Signal) bool {
+	if _, ok := sig.(hotRestartSignal); ok {
+		return true
+	}
+	for _, s := range hotRestartSignals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// loadInherited 解析从父进程继承的文件描述符
+func loadInherited() {
+	inherited.once.Do(func() {
+		inherited.files = map[string]*os.File{}
+		for _, item := range strings.Split(os.Getenv(envHotRestartListeners), ";") {
+			index := strings.LastIndex(item, "=")
+			if index == -1 {
+				continue
+			}
+			fd, err := strconv.Atoi(item[index+1:])
+			if err != nil {
+				continue
+			}
+			inherited.files[item[:index]] = os.NewFile(uintptr(fd), item[:index])
+		}
+		if fd, err := strconv.Atoi(os.Getenv(envHotRestartReady)); err == nil {
+			inherited.ready = os.NewFile(uintptr(fd), envHotRestartReady)
+		}
+		_ = os.Unsetenv(envHotRestartListeners)
+		_ = os.Unsetenv(envHotRestartReady)
+	})
+}
+
+// notifyHotRestartReady 当进程由热重启创建时，通知父进程已启动完成
+func notifyHotRestartReady() {
+	loadInherited()
+	inherited.l.Lock()
+	defer inherited.l.Unlock()
+	if inherited.ready == nil {
+		return
+	}
+	_, _ = inherited.ready.Write([]byte{1})
+	_ = inherited.ready.Close()
+	inherited.ready = nil
+}
+
+// listen 创建服务器侦听器，当进程由热重启创建时将优先使用从父进程继承的侦听器
+func (slf *Server) listen(network, addr string) (net.Listener, error) {
+	loadInherited()
+	key := fmt.Sprintf("%s://%s", slf.network, addr)
+	inherited.l.Lock()
+	file := inherited.files[key]
+	delete(inherited.files, key)
+	inherited.l.Unlock()
+
+	var listener net.Listener
+	var err error
+	if file != nil {
+		listener, err = net.FileListener(file)
+		_ = file.Close()
+		if err == nil {
+			log.Info("Server", log.String("listen", key), log.String("action", "hot restart"), log.String("state", "inherited"))
+		}
+	}
+	if listener == nil {
+		if listener, err = net.Listen(network, addr); err != nil {
+			return nil, err
+		}
+	}
+	slf.listenersL.Lock()
+	if slf.listeners == nil {
+		slf.listeners = map[string]net.Listener{}
+	}
+	slf.listeners[key] = listener
+	slf.listenersL.Unlock()
+	return listener, nil
+}
+
+// HotRestart 热重启服务器
+//   - 将以相同的参数启动一个新的进程，并将服务器的侦听器交接给新的进程，待新进程启动完成后，当前进程将停止接受新的连接
+//   - 随后当前进程将等待已有的连接断开，最长等待时间由 WithHotRestart 设置，之后将正常停止服务器
+//   - 也可以通过向进程发送 SIGUSR2 信号触发，仅支持 Linux 系统
+//   - 当服务器由 MultipleServer 运行时，将热重启 MultipleServer 中的所有服务器
+func (slf *Server) HotRestart() {
+	if slf.multiple != nil {
+		slf.multiple.HotRestart()
+		return
+	}
+	slf.systemSignal <- hotRestartSignal{}
+}
+
+// listenerFiles 获取服务器侦听器的文件描述符
+func (slf *Server) listenerFiles() (keys []string, files []*os.File, err error) {
+	slf.listenersL.Lock()
+	defer slf.listenersL.Unlock()
+	for key, listener := range slf.listeners {
+		filer, ok := listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		file, err := filer.File()
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		files = append(files, file)
+	}
+	return keys, files, nil
+}
+
+// closeListeners 关闭服务器侦听器，停止接受新的连接
+func (slf *Server) closeListeners() {
+	slf.listenersL.Lock()
+	defer slf.listenersL.Unlock()
+	for key, listener := range slf.listeners {
+		if err := listener.Close(); err != nil {
+			log.Error("Server", log.String("listen", key), log.Err(err))
+		}
+	}
+	slf.listeners = nil
+}
+
+// handoff 将服务器的侦听器交接给新启动的进程，并在交接完成后等待已有的连接断开
+func handoff(servers ...*Server) error {
+	var keys []string
+	var files []*os.File
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	for _, srv := range servers {
+		if srv.hotRestart == nil {
+			return fmt.Errorf("%w: %s://%s", ErrHotRestartNotEnabled, srv.network, srv.addr)
+		}
+		switch srv.network {
+		case NetworkHttp, NetworkWebsocket, NetworkGRPC, NetworkNone, NetworkTcp, NetworkTcp4, NetworkTcp6:
+		default:
+			return fmt.Errorf("%w: %s", ErrHotRestartNotSupportNetwork, srv.network)
+		}
+		k, f, err := srv.listenerFiles()
+		if err != nil {
+			return err
+		}
+		keys, files = append(keys, k...), append(files, f...)
+	}
+
+	for _, srv := range servers {
+		log.Info("Server", log.Any("network", srv.network), log.String("listen", srv.addr), log.String("action", "hot restart"), log.String("state", "starting"))
+	}
+	if err := startChild(keys, files); err != nil {
+		return err
+	}
+
+	var deadline time.Time
+	for _, srv := range servers {
+		srv.restarting.Store(true)
+		srv.closeListeners()
+		if d := time.Now().Add(srv.hotRestart.drain); d.After(deadline) {
+			deadline = d
+		}
+		log.Info("Server", log.Any("network", srv.network), log.String("listen", srv.addr), log.String("action", "hot restart"), log.String("state", "handed off"))
+	}
+	for time.Now().Before(deadline) {
+		var online int
+		for _, srv := range servers {
+			online += srv.GetOnlineCount()
+		}
+		if online == 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
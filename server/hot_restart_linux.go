@@ -0,0 +1,65 @@
+//go:build linux
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var hotRestartSignals = []os.Signal{syscall.SIGUSR2}
+
+// startChild 以相同的参数启动新的进程，并将侦听器的文件描述符交由其继承，直到新的进程启动完成或超时
+func startChild(keys []string, files []*os.File) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var listeners = make([]string, len(keys))
+	for i, key := range keys {
+		listeners[i] = fmt.Sprintf("%s=%d", key, 3+i)
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(append([]*os.File{}, files...), writer)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", envHotRestartListeners, strings.Join(listeners, ";")),
+		fmt.Sprintf("%s=%d", envHotRestartReady, 3+len(files)),
+	)
+	err = cmd.Start()
+	_ = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	var ready = make(chan error, 1)
+	go func() {
+		var buf = make([]byte, 1)
+		if n, err := reader.Read(buf); n != 1 {
+			ready <- fmt.Errorf("%w: %v", ErrHotRestartChildFailed, err)
+			return
+		}
+		ready <- nil
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(DefaultHotRestartReadyTimeout):
+		err = fmt.Errorf("%w: wait ready timeout", ErrHotRestartChildFailed)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_, _ = cmd.Process.Wait()
+		return err
+	}
+	return cmd.Process.Release()
+}
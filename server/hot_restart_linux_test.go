@@ -0,0 +1,273 @@
+//go:build linux
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// resetInherited 重置从父进程继承的文件描述符，使 loadInherited 重新解析环境变量
+func resetInherited() {
+	inherited.l.Lock()
+	defer inherited.l.Unlock()
+	inherited.once = sync.Once{}
+	inherited.files = nil
+	inherited.ready = nil
+}
+
+// dup 复制文件描述符并关闭原有的文件
+func dup(t *testing.T, file *os.File) int {
+	defer file.Close()
+	fd, err := syscall.Dup(int(file.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func TestServer_listen(t *testing.T) {
+	defer resetInherited()
+	origin, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer origin.Close()
+	file, err := origin.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	// 继承的文件描述符将由 loadInherited 接管并关闭，因此需要复制后释放原有的文件
+	srv := New(NetworkHttp)
+	addr := origin.Addr().String()
+	t.Setenv(envHotRestartListeners, fmt.Sprintf("%s://%s=%d", srv.network, addr, dup(t, file)))
+	t.Setenv(envHotRestartReady, fmt.Sprint(dup(t, writer)))
+	resetInherited()
+
+	// 原有侦听器依旧占用该地址，只有继承的侦听器能够成功创建
+	listener, err := srv.listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listener.Addr().String() != addr {
+		t.Fatal(listener.Addr(), addr)
+	}
+	if len(os.Getenv(envHotRestartListeners))+len(os.Getenv(envHotRestartReady)) > 0 {
+		t.Fatal("inherited environment not cleared")
+	}
+	if _, err = srv.listen("tcp", addr); err == nil {
+		t.Fatal("inherited listener reused")
+	}
+	keys, files, err := srv.listenerFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		_ = f.Close()
+	}
+	if len(keys) != 1 || keys[0] != fmt.Sprintf("%s://%s", srv.network, addr) {
+		t.Fatal(keys)
+	}
+	srv.closeListeners()
+
+	notifyHotRestartReady()
+	var buf = make([]byte, 1)
+	_ = reader.SetReadDeadline(time.Now().Add(time.Second))
+	if n, err := reader.Read(buf); n != 1 || err != nil {
+		t.Fatal(n, err)
+	}
+}
+
+func TestHandoff_NotSupported(t *testing.T) {
+	if err := handoff(New(NetworkWebsocket)); !errors.Is(err, ErrHotRestartNotEnabled) {
+		t.Fatal(err)
+	}
+	for _, network := range []Network{NetworkUdp, NetworkUnix, NetworkKcp} {
+		if err := handoff(New(network, WithHotRestart(time.Second))); !errors.Is(err, ErrHotRestartNotSupportNetwork) {
+			t.Fatal(network, err)
+		}
+	}
+}
+
+func TestServer_HotRestart_Multiple(t *testing.T) {
+	srv := New(NetworkWebsocket, WithHotRestart(time.Second))
+	multiple := NewMultipleServer(func() (addr string, s *Server) {
+		return ":0", srv
+	})
+	srv.multiple = multiple
+	// 由 MultipleServer 运行的服务器不会读取自身的信号，热重启将交由 MultipleServer 处理
+	srv.HotRestart()
+	select {
+	case sig := <-multiple.systemSignal:
+		if !isHotRestartSignal(sig) {
+			t.Fatal(sig)
+		}
+	default:
+		t.Fatal("hot restart not forwarded to multiple server")
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	const addr = ":9980"
+	if os.Getenv(envHotRestartListeners) != "" {
+		// 作为热重启的新进程运行，继承侦听器后提供短暂的服务
+		srv := New(NetworkHttp)
+		srv.HttpRouter().GET("/", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "child")
+		})
+		time.AfterFunc(3*time.Second, srv.Shutdown)
+		if err := srv.Run(addr); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	var started = make(chan struct{})
+	srv := New(NetworkHttp, WithHotRestart(time.Second))
+	srv.HttpRouter().GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "parent")
+	})
+	srv.RegStartFinishEvent(func(srv *Server) {
+		close(started)
+	})
+	go func() {
+		if err := srv.Run(addr); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	var cli = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	get := func() string {
+		resp, err := cli.Get("http://127.0.0.1" + addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := get(); body != "parent" {
+		t.Fatal(body)
+	}
+
+	// 新进程仅运行当前测试
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^TestHandoff$"}
+	defer func() {
+		os.Args = args
+	}()
+	if err := handoff(srv); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.restarting.Load() {
+		t.Fatal("server not marked as restarting")
+	}
+	for i := 0; i < 3; i++ {
+		if body := get(); body != "child" {
+			t.Fatal(body)
+		}
+	}
+}
+
+func TestHandoff_TCP(t *testing.T) {
+	const addr = ":9978"
+	echo := func(mark string) ConnectionReceivePacketEventHandle {
+		return func(srv *Server, conn *Conn, packet []byte) {
+			conn.Write(append([]byte(mark+":"), packet...))
+		}
+	}
+	if os.Getenv(envHotRestartListeners) != "" {
+		// 作为热重启的新进程运行，继承侦听器后提供短暂的服务
+		srv := New(NetworkTcp, WithHotRestart(time.Second))
+		srv.RegConnectionReceivePacketEvent(echo("child"))
+		time.AfterFunc(3*time.Second, srv.Shutdown)
+		if err := srv.Run(addr); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	var ready = make(chan struct{})
+	srv := New(NetworkTcp, WithHotRestart(3*time.Second))
+	srv.RegConnectionReceivePacketEvent(echo("parent"))
+	srv.RegMessageReadyEvent(func(srv *Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(addr); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	ping := func(conn net.Conn, expect string) {
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		var buf = make([]byte, 64)
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil || string(buf[:n]) != expect+":ping" {
+			t.Fatal(string(buf[:n]), err)
+		}
+	}
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", "127.0.0.1"+addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	existing := dial()
+	defer existing.Close()
+	ping(existing, "parent")
+
+	// 新进程仅运行当前测试
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^TestHandoff_TCP$"}
+	defer func() {
+		os.Args = args
+	}()
+	// 当前进程将等待已有的连接断开，期间新的连接由新进程处理，已有的连接依旧由当前进程处理
+	var handed = make(chan error, 1)
+	go func() {
+		handed <- handoff(srv)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); !srv.restarting.Load(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("listener not handed off")
+		}
+	}
+	for i := 0; i < 3; i++ {
+		conn := dial()
+		ping(conn, "child")
+		_ = conn.Close()
+	}
+	ping(existing, "parent")
+	_ = existing.Close()
+	if err := <-handed; err != nil {
+		t.Fatal(err)
+	}
+}
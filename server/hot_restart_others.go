@@ -0,0 +1,14 @@
+//go:build !linux
+
+package server
+
+import (
+	"os"
+)
+
+var hotRestartSignals []os.Signal
+
+// startChild 热重启仅支持 Linux 系统
+func startChild(keys []string, files []*os.File) error {
+	return ErrHotRestartNotSupported
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"time"
+)
+
+// idleDetect 空闲连接检测配置
+type idleDetect struct {
+	timeout  time.Duration           // 空闲超时时间
+	interval time.Duration           // 心跳间隔
+	ping     func(conn *Conn) []byte // 心跳数据包生成函数
+}
+
+// touch 更新连接最近一次接收到数据的时间
+func (slf *Conn) touch() {
+	slf.lastReceive.Store(time.Now().UnixNano())
+}
+
+// GetLastReceiveTime 获取连接最近一次接收到数据的时间
+func (slf *Conn) GetLastReceiveTime() time.Time {
+	return time.Unix(0, slf.lastReceive.Load())
+}
+
+// idleCheckInterval 获取空闲连接检测的间隔，未开启空闲连接检测时返回 0
+func (slf *Server) idleCheckInterval() time.Duration {
+	if slf.idle == nil || (slf.idle.timeout <= 0 && slf.idle.interval <= 0) {
+		return 0
+	}
+	var interval = slf.idle.timeout
+	if interval <= 0 || (slf.idle.interval > 0 && slf.idle.interval < interval) {
+		interval = slf.idle.interval
+	}
+	return max(interval/2, 10*time.Millisecond)
+}
+
+// runIdleDetect 运行空闲连接检测，检测将通过系统消息在服务器消息中进行
+//   - 基于 gnet 的网络类型将由 gNet.Tick 驱动检测
+func (slf *Server) runIdleDetect() {
+	interval := slf.idleCheckInterval()
+	if interval <= 0 || slf.isGNetServed() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if slf.isShutdown.Load() {
+				return
+			}
+			PushSystemMessage(slf, slf.checkIdle, "IdleDetect")
+		}
+	}()
+}
+
+// checkIdle 关闭超出空闲时间的连接，并向空闲的连接发送心跳数据包
+func (slf *Server) checkIdle() {
+	var now = time.Now()
+	for _, conn := range slf.online.Slice() {
+		if conn.IsClosed() {
+			continue
+		}
+		idle := now.Sub(conn.GetLastReceiveTime())
+		if slf.idle.timeout > 0 && idle >= slf.idle.timeout {
+			conn.Close(ErrConnectionIdleTimeout)
+			continue
+		}
+		if slf.idle.interval > 0 && idle >= slf.idle.interval && now.Sub(conn.lastPing) >= slf.idle.interval {
+			conn.lastPing = now
+			conn.Write(slf.idle.ping(conn))
+		}
+	}
+}
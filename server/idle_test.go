@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"testing"
+	"time"
+)
+
+func TestWithConnectionIdleTimeout(t *testing.T) {
+	var closed = make(chan any, 1)
+	var pings = make(chan struct{}, 10)
+	srv := server.New(server.NetworkWebsocket,
+		server.WithConnectionIdleTimeout(time.Second),
+		server.WithConnectionPing(time.Millisecond*300, func(conn *server.Conn) []byte {
+			return []byte("ping")
+		}),
+	)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- err
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		cli := client.NewWebsocket("ws://127.0.0.1:9995")
+		cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+			pings <- struct{}{}
+		})
+		if err := cli.Run(); err != nil {
+			t.Error(err)
+		}
+	})
+	go func() {
+		if err := srv.Run(":9995"); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	select {
+	case err := <-closed:
+		if e, ok := err.(error); !ok || !errors.Is(e, server.ErrConnectionIdleTimeout) {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	}
+	if len(pings) == 0 {
+		t.Fatal("no ping received")
+	}
+	srv.Shutdown()
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"github.com/xtaci/kcp-go/v5"
+	"time"
+)
+
+const (
+	DefaultKCPReadBufferSize = 4096 // 默认 KCP 连接读取缓冲区大小
+)
+
+// KCPPreset KCP 参数预设
+type KCPPreset int
+
+const (
+	KCPPresetNormal KCPPreset = iota // 普通模式，即 KCP 的标准模式，关闭 nodelay，40ms 刷新间隔，关闭快速重传并开启拥塞控制，适用于对延迟不敏感的场景
+	KCPPresetFast                    // 快速模式，关闭 nodelay，30ms 刷新间隔，开启快速重传并关闭拥塞控制
+	KCPPresetTurbo                   // 极速模式，开启 nodelay，10ms 刷新间隔及 ACK 立即发送，适用于动作类游戏，将占用更多的带宽
+)
+
+// NewKCPOptions 基于预设创建 KCP 参数，可在此基础上调整后通过 WithKCPOptions 使用
+func NewKCPOptions(preset KCPPreset) *KCPOptions {
+	options := &KCPOptions{MTU: 1350, ReadBufferSize: DefaultKCPReadBufferSize}
+	switch preset {
+	case KCPPresetFast:
+		options.NoDelay, options.Interval, options.Resend, options.NoCongestion = 0, 30, 2, 1
+		options.SndWnd, options.RcvWnd = 128, 512
+	case KCPPresetTurbo:
+		options.NoDelay, options.Interval, options.Resend, options.NoCongestion = 1, 10, 2, 1
+		options.SndWnd, options.RcvWnd = 512, 1024
+		options.ACKNoDelay = true
+	default:
+		options.NoDelay, options.Interval, options.Resend, options.NoCongestion = 0, 40, 0, 0
+		options.SndWnd, options.RcvWnd = 32, 128
+	}
+	return options
+}
+
+// KCPOptions KCP 会话参数，服务端与客户端需要使用相同的 Block、DataShards 及 ParityShards
+type KCPOptions struct {
+	Block          kcp.BlockCrypt // 数据包加密方式，例如 kcp.NewAESBlockCrypt，为 nil 时不加密
+	DataShards     int            // 前向纠错的数据分片数量，为 0 时不开启前向纠错
+	ParityShards   int            // 前向纠错的校验分片数量，为 0 时不开启前向纠错
+	NoDelay        int            // 是否开启 nodelay 模式，0 关闭，1 开启
+	Interval       int            // 内部刷新间隔，单位为毫秒
+	Resend         int            // 快速重传的 ACK 跨越次数，0 表示关闭快速重传
+	NoCongestion   int            // 是否关闭拥塞控制，0 不关闭，1 关闭
+	SndWnd         int            // 发送窗口大小
+	RcvWnd         int            // 接收窗口大小
+	MTU            int            // 最大传输单元，小于等于 0 时使用 KCP 默认值
+	ACKNoDelay     bool           // 是否立即发送 ACK
+	SocketBuffer   int            // UDP 套接字的读写缓冲区大小，小于等于 0 时使用系统默认值
+	ReadBufferSize int            // 连接读取缓冲区大小，小于等于 0 时使用 DefaultKCPReadBufferSize
+}
+
+// Apply 将参数应用到 KCP 会话
+func (slf *KCPOptions) Apply(session *kcp.UDPSession) {
+	if slf == nil {
+		return
+	}
+	session.SetNoDelay(slf.NoDelay, slf.Interval, slf.Resend, slf.NoCongestion)
+	if slf.SndWnd > 0 && slf.RcvWnd > 0 {
+		session.SetWindowSize(slf.SndWnd, slf.RcvWnd)
+	}
+	if slf.MTU > 0 {
+		session.SetMtu(slf.MTU)
+	}
+	session.SetACKNoDelay(slf.ACKNoDelay)
+}
+
+// GetReadBufferSize 获取连接读取缓冲区大小
+func (slf *KCPOptions) GetReadBufferSize() int {
+	if slf == nil || slf.ReadBufferSize <= 0 {
+		return DefaultKCPReadBufferSize
+	}
+	return slf.ReadBufferSize
+}
+
+// listen 通过参数侦听 KCP 连接
+func (slf *KCPOptions) listen(addr string) (*kcp.Listener, error) {
+	if slf == nil {
+		return kcp.ListenWithOptions(addr, nil, 0, 0)
+	}
+	listener, err := kcp.ListenWithOptions(addr, slf.Block, slf.DataShards, slf.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	if slf.SocketBuffer > 0 {
+		_ = listener.SetReadBuffer(slf.SocketBuffer)
+		_ = listener.SetWriteBuffer(slf.SocketBuffer)
+	}
+	return listener, nil
+}
+
+// KCPStats KCP 会话统计
+type KCPStats struct {
+	Conv    uint32        // 会话ID
+	SRTT    time.Duration // 平滑往返时间
+	SRTTVar time.Duration // 往返时间偏差
+	RTO     time.Duration // 重传超时时间
+}
+
+// GetKCPStats 获取连接的 KCP 会话统计，非 KCP 连接将返回 false
+//   - kcp-go 未提供单个会话的重传计数，重传等计数仅能通过 GetKCPSnmp 获取进程内所有会话的累计值
+func (slf *Conn) GetKCPStats() (stats KCPStats, ok bool) {
+	if slf.kcp == nil {
+		return stats, false
+	}
+	return KCPStats{
+		Conv:    slf.kcp.GetConv(),
+		SRTT:    time.Duration(slf.kcp.GetSRTT()) * time.Millisecond,
+		SRTTVar: time.Duration(slf.kcp.GetSRTTVar()) * time.Millisecond,
+		RTO:     time.Duration(slf.kcp.GetRTO()) * time.Millisecond,
+	}, true
+}
+
+// GetKCPSnmp 获取 KCP 的全局统计快照，包含重传、丢包及前向纠错恢复等计数
+//   - 该统计为当前进程中所有 KCP 会话的累计值，kcp-go 未提供单个会话的重传计数
+func GetKCPSnmp() *kcp.Snmp {
+	return kcp.DefaultSnmp.Copy()
+}
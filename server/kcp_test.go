@@ -0,0 +1,83 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"github.com/xtaci/kcp-go/v5"
+	"testing"
+	"time"
+)
+
+func TestWithKCPOptions(t *testing.T) {
+	block, err := kcp.NewAESBlockCrypt([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := server.NewKCPOptions(server.KCPPresetTurbo)
+	options.Block, options.DataShards, options.ParityShards = block, 10, 3
+
+	var stats = make(chan server.KCPStats, 1)
+	srv := server.New(server.NetworkKcp, server.WithKCPOptions(options))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if s, ok := conn.GetKCPStats(); ok {
+			select {
+			case stats <- s:
+			default:
+			}
+		}
+		conn.Write(packet)
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9987"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	var received = make(chan string, 1)
+	cli := client.NewKCP("127.0.0.1:9987", options)
+	cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		received <- string(packet)
+	})
+	if err = cli.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	cli.Write([]byte("hello"))
+	select {
+	case packet := <-received:
+		if packet != "hello" {
+			t.Fatal(packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("echo timeout")
+	}
+	if s := <-stats; s.Conv == 0 || s.RTO <= 0 {
+		t.Fatal(s)
+	}
+	if _, ok := server.NewEmptyConn(srv).GetKCPStats(); ok {
+		t.Fatal("empty connection reported kcp stats")
+	}
+}
+
+func TestNewKCPOptions(t *testing.T) {
+	for preset, expect := range map[server.KCPPreset][4]int{
+		server.KCPPresetNormal: {0, 40, 0, 0},
+		server.KCPPresetFast:   {0, 30, 2, 1},
+		server.KCPPresetTurbo:  {1, 10, 2, 1},
+	} {
+		options := server.NewKCPOptions(preset)
+		if actual := [4]int{options.NoDelay, options.Interval, options.Resend, options.NoCongestion}; actual != expect {
+			t.Fatal(preset, actual, expect)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"time"
+)
+
+// maintenance 维护模式及停机排空配置
+type maintenance struct {
+	packet  []byte                  // 拒绝新连接时发送的数据包
+	timeout time.Duration           // 停机排空的最长等待时间
+	notify  func(conn *Conn) []byte // 停机排空时通知已连接客户端的数据包生成函数
+}
+
+// EnterMaintenance 进入维护模式
+//   - 维护模式下新的连接将被拒绝，如果通过 WithMaintenancePacket 设置了维护数据包，将在关闭新连接前发送该数据包
+//   - 已建立的连接不受影响
+func (slf *Server) EnterMaintenance() {
+	if slf.maintaining.CompareAndSwap(false, true) {
+		log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr), log.String("action", "maintenance"), log.String("state", "enter"))
+	}
+}
+
+// ExitMaintenance 退出维护模式，恢复接受新的连接
+func (slf *Server) ExitMaintenance() {
+	if slf.maintaining.CompareAndSwap(true, false) {
+		log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr), log.String("action", "maintenance"), log.String("state", "exit"))
+	}
+}
+
+// IsMaintenance 是否处于维护模式
+func (slf *Server) IsMaintenance() bool {
+	return slf.maintaining.Load()
+}
+
+// refuse 检查是否应拒绝新的连接，服务器处于维护模式、正在停机或已通过热重启交接侦听器时将拒绝新的连接
+//   - packet 为拒绝前需要发送给该连接的维护数据包，在非 Websocket 网络中将经过编解码器封包
+func (slf *Server) refuse() (packet []byte, refused bool) {
+	if !slf.maintaining.Load() && !slf.isShutdown.Load() && !slf.restarting.Load() {
+		return nil, false
+	}
+	if slf.maintenance == nil || len(slf.maintenance.packet) == 0 {
+		return nil, true
+	}
+	packet = slf.maintenance.packet
+	if slf.network != NetworkWebsocket && slf.packetCodec != nil {
+		encoded, err := slf.packetCodec.Encode(packet)
+		if err != nil {
+			log.Error("Server", log.String("action", "maintenance"), log.Err(err))
+			return nil, true
+		}
+		packet = encoded
+	}
+	return packet, true
+}
+
+// drain 停机时排空服务器，向已连接的客户端发送通知，并在超时时间内等待消息处理完成及写入队列清空
+func (slf *Server) drain() {
+	var deadline time.Time
+	if slf.maintenance != nil && slf.maintenance.timeout > 0 {
+		deadline = time.Now().Add(slf.maintenance.timeout)
+		if notify := slf.maintenance.notify; notify != nil {
+			for _, conn := range slf.online.Slice() {
+				if packet := notify(conn); len(packet) > 0 {
+					conn.Write(packet)
+				}
+			}
+		}
+	}
+
+	var report time.Time
+	for {
+		var messages, pending = slf.messageCounter.Load(), 0
+		if !deadline.IsZero() {
+			for _, conn := range slf.online.Slice() {
+				pending += conn.GetPendingPackets()
+			}
+		}
+		if messages <= 0 && pending == 0 {
+			return
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			log.Warn("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+				log.String("action", "shutdown"), log.String("state", "drain timeout"), log.Int64("message", messages), log.Int("packet", pending))
+			return
+		}
+		if time.Since(report) >= time.Second {
+			report = time.Now()
+			log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+				log.String("action", "shutdown"), log.String("state", "waiting"), log.Int64("message", messages), log.Int("packet", pending))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
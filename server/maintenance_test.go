@@ -0,0 +1,52 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer_EnterMaintenance(t *testing.T) {
+	var opened = make(chan struct{}, 1)
+	srv := server.New(server.NetworkTcp, server.WithMaintenancePacket([]byte("maintenance")))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened <- struct{}{}
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9993"); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	srv.EnterMaintenance()
+	conn, err := net.Dial("tcp", "127.0.0.1:9993")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packet, err := io.ReadAll(conn)
+	if err != nil || string(packet) != "maintenance" {
+		t.Fatal(string(packet), err)
+	}
+	if len(opened) > 0 {
+		t.Fatal("connection opened during maintenance")
+	}
+	srv.ExitMaintenance()
+	if srv.IsMaintenance() {
+		t.Fatal("still in maintenance")
+	}
+	srv.Shutdown()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/kercylan98/minotaur/utils/hash"
 	"github.com/kercylan98/minotaur/utils/super"
 	"reflect"
+	"time"
 )
 
 const (
@@ -29,6 +30,9 @@ const (
 
 	// MessageTypeSystem 系统消息类型
 	MessageTypeSystem
+
+	// MessageTypeCrossRequest 跨服请求消息类型：将被推送到 ReceiveCrossRequestEvent 进行处理并回复请求方
+	MessageTypeCrossRequest
 )
 
 var messageNames = map[MessageType]string{
@@ -39,6 +43,7 @@ var messageNames = map[MessageType]string{
 	MessageTypeAsync:         "MessageTypeAsync",
 	MessageTypeAsyncCallback: "MessageTypeAsyncCallback",
 	MessageTypeSystem:        "MessageTypeSystem",
+	MessageTypeCrossRequest:  "MessageTypeCrossRequest",
 }
 
 const (
@@ -78,6 +83,8 @@ func (slf MessageErrorAction) String() string {
 type Message struct {
 	t     MessageType // 消息类型
 	attrs []any       // 消息属性
+	epoch *shuntEpoch // 分流通道绑定批次
+	since int64       // 进入分流通道的时间
 }
 
 // MessageType 返回消息类型
@@ -93,7 +100,14 @@ func (slf *Message) AttrsString() string {
 			attrs = append(attrs, tof.String())
 			continue
 		}
-		attrs = append(attrs, attr)
+		switch v := attr.(type) {
+		case *Conn:
+			attrs = append(attrs, v.GetID())
+		case []byte:
+			attrs = append(attrs, messagePacketVisualization(v))
+		default:
+			attrs = append(attrs, attr)
+		}
 	}
 	if len(attrs) == 0 {
 		return "NoneAttr"
@@ -128,6 +142,7 @@ func (slf *Message) GetPacketMessageAttrs() (conn *Conn, packet []byte) {
 
 // PushPacketMessage 向特定服务器中推送 MessageTypePacket 消息
 func PushPacketMessage(srv *Server, conn *Conn, wst int, packet []byte, mark ...any) {
+	srv.recorder.record(conn, PacketRecordInbound, wst, packet)
 	msg := srv.messagePool.Get()
 	msg.t = MessageTypePacket
 	msg.attrs = append([]any{&Conn{ctx: context.WithValue(conn.ctx, contextKeyWST, wst), connection: conn.connection}, packet}, mark...)
@@ -175,6 +190,58 @@ func PushCrossMessage(srv *Server, crossName string, serverId int64, packet []by
 	}
 }
 
+// GetCrossRequestMessageAttrs 获取消息中的跨服请求属性
+func (slf *Message) GetCrossRequestMessageAttrs() (serverId int64, packet []byte, reply func(packet []byte) error) {
+	serverId = slf.attrs[0].(int64)
+	packet = slf.attrs[1].([]byte)
+	reply = slf.attrs[2].(func(packet []byte) error)
+	return
+}
+
+// PushCrossRequest 通过特定的跨服中间件向目标服务器发送跨服请求
+//   - 请求将在服务器的异步消息队列中阻塞等待回复，回复或错误将通过 callback 函数在服务器消息中返回
+//   - 由于依赖异步消息，通过 WithDisableAsyncMessage 禁用异步消息时将无法使用该函数
+func PushCrossRequest(srv *Server, crossName string, serverId int64, packet []byte, timeout time.Duration, callback func(reply []byte, err error), mark ...any) {
+	var reply []byte
+	PushAsyncMessage(srv, func() error {
+		cross, exist := srv.cross[crossName]
+		if !exist {
+			return ErrNoSupportCross
+		}
+		var err error
+		reply, err = cross.Request(serverId, packet, timeout)
+		return err
+	}, func(err error) {
+		if callback != nil {
+			callback(reply, err)
+		}
+	}, mark...)
+}
+
+// PushCrossBroadcast 通过特定的跨服中间件向所有服务器推送跨服消息
+func PushCrossBroadcast(srv *Server, crossName string, packet []byte) {
+	if len(srv.cross) == 0 {
+		return
+	}
+	cross, exist := srv.cross[crossName]
+	if !exist {
+		return
+	}
+	_ = cross.Broadcast(packet)
+}
+
+// PushCrossGroupMessage 通过特定的跨服中间件向特定分组中的所有服务器推送跨服消息
+func PushCrossGroupMessage(srv *Server, crossName string, group string, packet []byte) {
+	if len(srv.cross) == 0 {
+		return
+	}
+	cross, exist := srv.cross[crossName]
+	if !exist {
+		return
+	}
+	_ = cross.PushGroupMessage(group, packet)
+}
+
 // GetTickerMessageAttrs 获取消息中的定时器属性
 func (slf *Message) GetTickerMessageAttrs() (caller func()) {
 	caller = slf.attrs[0].(func())
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultMetricsPattern = "/metrics"
+)
+
+// metricsDurationBuckets 消息执行耗时直方图的桶边界，单位为秒
+var metricsDurationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// newMetrics 创建服务器指标
+func newMetrics(addr, pattern string) *metrics {
+	m := &metrics{
+		addr:     addr,
+		pattern:  pattern,
+		messages: map[MessageType]*messageMetrics{},
+	}
+	for t := range messageNames {
+		m.messages[t] = &messageMetrics{buckets: make([]atomic.Int64, len(metricsDurationBuckets))}
+	}
+	return m
+}
+
+// metrics 服务器指标
+//   - 所有函数均允许在 nil 上调用，此时将不进行任何记录
+type metrics struct {
+	addr     string                          // 独立侦听的地址
+	pattern  string                          // 指标路由
+	server   *http.Server                    // 独立侦听的 HTTP 服务器
+	messages map[MessageType]*messageMetrics // 各消息类型的指标
+
+	bytesIn       atomic.Int64 // 接收的字节数
+	bytesOut      atomic.Int64 // 写入的字节数
+	packetsIn     atomic.Int64 // 接收的数据包数量
+	packetsOut    atomic.Int64 // 写入的数据包数量
+	packetsDrop   atomic.Int64 // 因写入队列已满而丢弃的数据包数量
+	connOpened    atomic.Int64 // 打开的连接数量
+	connClosed    atomic.Int64 // 关闭的连接数量
+	messageCancel atomic.Int64 // 被 MessageExecBeforeEvent 拦截的消息数量
+}
+
+// messageMetrics 特定消息类型的指标
+type messageMetrics struct {
+	count   atomic.Int64   // 执行完成的消息数量
+	errors  atomic.Int64   // 执行过程中发生异常的消息数量
+	sum     atomic.Int64   // 执行耗时总和，单位为纳秒
+	buckets []atomic.Int64 // 各桶内的消息数量，非累计值
+}
+
+// message 记录一条消息的执行耗时
+func (slf *metrics) message(t MessageType, cost time.Duration, panicked bool) {
+	if slf == nil {
+		return
+	}
+	m, exist := slf.messages[t]
+	if !exist {
+		return
+	}
+	m.count.Add(1)
+	m.sum.Add(int64(cost))
+	if panicked {
+		m.errors.Add(1)
+	}
+	seconds := cost.Seconds()
+	for i, bound := range metricsDurationBuckets {
+		if seconds <= bound {
+			m.buckets[i].Add(1)
+			break
+		}
+	}
+}
+
+// received 记录连接接收的数据字节数
+func (slf *metrics) received(n int) {
+	if slf == nil {
+		return
+	}
+	slf.bytesIn.Add(int64(n))
+}
+
+// receivedPacket 记录连接接收的完整数据包，应在分包处理后调用
+func (slf *metrics) receivedPacket() {
+	if slf == nil {
+		return
+	}
+	slf.packetsIn.Add(1)
+}
+
+// written 记录写入连接的数据
+func (slf *metrics) written(n int) {
+	if slf == nil {
+		return
+	}
+	slf.packetsOut.Add(1)
+	slf.bytesOut.Add(int64(n))
+}
+
+// dropped 记录因写入队列已满而丢弃的数据包
+func (slf *metrics) dropped() {
+	if slf == nil {
+		return
+	}
+	slf.packetsDrop.Add(1)
+}
+
+// connection 记录连接的打开或关闭
+func (slf *metrics) connection(opened bool) {
+	if slf == nil {
+		return
+	}
+	if opened {
+		slf.connOpened.Add(1)
+	} else {
+		slf.connClosed.Add(1)
+	}
+}
+
+// cancel 记录被拦截的消息
+func (slf *metrics) cancel() {
+	if slf == nil {
+		return
+	}
+	slf.messageCancel.Add(1)
+}
+
+// runMetrics 在独立的地址上运行指标服务器
+func (slf *Server) runMetrics() {
+	if slf.metrics == nil || len(slf.metrics.addr) == 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(slf.metrics.pattern, slf.MetricsHandler())
+	slf.metrics.server = &http.Server{Addr: slf.metrics.addr, Handler: mux}
+	go func(srv *http.Server) {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Server", log.String("Metrics", srv.Addr), log.Err(err))
+		}
+	}(slf.metrics.server)
+}
+
+// stopMetrics 停止独立运行的指标服务器
+func (slf *Server) stopMetrics() {
+	if slf.metrics == nil || slf.metrics.server == nil {
+		return
+	}
+	if err := slf.metrics.server.Close(); err != nil {
+		log.Error("Server", log.String("Metrics", slf.metrics.addr), log.Err(err))
+	}
+}
+
+// MetricsHandler 获取以 Prometheus 文本格式输出服务器指标的 http.Handler
+//   - 需要通过 WithMetrics 开启指标收集，否则仅输出在线连接数等基础指标
+//   - 可将其注册到任意 HTTP 服务器中
+func (slf *Server) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = slf.WriteMetrics(writer)
+	})
+}
+
+// WriteMetrics 以 Prometheus 文本格式将服务器指标写入 writer
+func (slf *Server) WriteMetrics(writer io.Writer) error {
+	var buf bytes.Buffer
+	var gauge = func(name, help string, value int64) {
+		writeMetricsHeader(&buf, name, help, "gauge")
+		fmt.Fprintf(&buf, "%s %d\n", name, value)
+	}
+	var counter = func(name, help string, value int64) {
+		writeMetricsHeader(&buf, name, help, "counter")
+		fmt.Fprintf(&buf, "%s %d\n", name, value)
+	}
+
+	var depth int
+	if channel := slf.messageChannel; channel != nil {
+		depth = len(channel)
+	}
+	gauge("minotaur_online_connections", "Number of online connections.", int64(slf.GetOnlineCount()))
+	gauge("minotaur_messages_pending", "Number of messages that have been pushed but not yet processed.", slf.GetMessageCount())
+	gauge("minotaur_message_queue_depth", "Number of messages waiting in the system message channel.", int64(depth))
+
+	m := slf.metrics
+	if m == nil {
+		_, err := writer.Write(buf.Bytes())
+		return err
+	}
+	counter("minotaur_connections_opened_total", "Total number of opened connections.", m.connOpened.Load())
+	counter("minotaur_connections_closed_total", "Total number of closed connections.", m.connClosed.Load())
+	counter("minotaur_received_bytes_total", "Total number of bytes received from connections.", m.bytesIn.Load())
+	counter("minotaur_received_packets_total", "Total number of packets received from connections.", m.packetsIn.Load())
+	counter("minotaur_written_bytes_total", "Total number of bytes written to connections.", m.bytesOut.Load())
+	counter("minotaur_written_packets_total", "Total number of packets written to connections.", m.packetsOut.Load())
+	counter("minotaur_dropped_packets_total", "Total number of outbound packets dropped because the write queue was full.", m.packetsDrop.Load())
+	counter("minotaur_messages_canceled_total", "Total number of messages canceled by MessageExecBeforeEvent.", m.messageCancel.Load())
+
+	var types = make([]MessageType, 0, len(m.messages))
+	for t := range m.messages {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	writeMetricsHeader(&buf, "minotaur_messages_total", "Total number of processed messages by type.", "counter")
+	for _, t := range types {
+		fmt.Fprintf(&buf, "minotaur_messages_total{type=%q} %d\n", messageNames[t], m.messages[t].count.Load())
+	}
+	writeMetricsHeader(&buf, "minotaur_message_errors_total", "Total number of messages that panicked by type.", "counter")
+	for _, t := range types {
+		fmt.Fprintf(&buf, "minotaur_message_errors_total{type=%q} %d\n", messageNames[t], m.messages[t].errors.Load())
+	}
+	writeMetricsHeader(&buf, "minotaur_message_duration_seconds", "Message processing latency by type.", "histogram")
+	for _, t := range types {
+		var name, mm, cumulative = messageNames[t], m.messages[t], int64(0)
+		for i, bound := range metricsDurationBuckets {
+			cumulative += mm.buckets[i].Load()
+			fmt.Fprintf(&buf, "minotaur_message_duration_seconds_bucket{type=%q,le=%q} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		count := mm.count.Load()
+		fmt.Fprintf(&buf, "minotaur_message_duration_seconds_bucket{type=%q,le=\"+Inf\"} %d\n", name, count)
+		fmt.Fprintf(&buf, "minotaur_message_duration_seconds_sum{type=%q} %s\n", name, strconv.FormatFloat(time.Duration(mm.sum.Load()).Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&buf, "minotaur_message_duration_seconds_count{type=%q} %d\n", name, count)
+	}
+
+	_, err := writer.Write(buf.Bytes())
+	return err
+}
+
+// writeMetricsHeader 写入指标的 HELP 及 TYPE 信息
+func writeMetricsHeader(buf *bytes.Buffer, name, help, t string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, t)
+}
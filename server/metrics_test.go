@@ -0,0 +1,112 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	var done = make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithMetrics(":9994"))
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		server.PushSystemMessage(srv, func() {
+			close(done)
+		})
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	resp, err := http.Get("http://127.0.0.1:9994/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"# TYPE minotaur_message_duration_seconds histogram",
+		`minotaur_message_duration_seconds_bucket{type="MessageTypeSystem",le="+Inf"}`,
+		"minotaur_online_connections 0",
+	} {
+		if !strings.Contains(string(body), expect) {
+			t.Fatal(expect, "\n", string(body))
+		}
+	}
+	srv.Shutdown()
+}
+
+func TestWithMetrics_ReceivedPackets(t *testing.T) {
+	var received = make(chan struct{}, 2)
+	srv := server.New(server.NetworkTcp, server.WithMetrics(":9981"), server.WithPacketCodec(server.NewLengthFieldCodec()))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- struct{}{}
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9982"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9982")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// 两个数据包通过一次写入发送，指标应按分包后的数据包计数
+	codec := server.NewLengthFieldCodec()
+	a, _ := codec.Encode([]byte("a"))
+	b, _ := codec.Encode([]byte("b"))
+	if _, err = conn.Write(append(a, b...)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	resp, err := http.Get("http://127.0.0.1:9981/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"minotaur_received_packets_total 2\n",
+		"minotaur_received_bytes_total 10\n",
+	} {
+		if !strings.Contains(string(body), expect) {
+			t.Fatal(expect, "\n", string(body))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+// PacketMiddleware 数据包中间件
+//   - next 用于调用下一个中间件，最后一个中间件的 next 将触发 ConnectionPacketPreprocessEvent 及 ConnectionReceivePacketEvent
+//   - 不调用 next 时将中断后续的处理，可通过向 next 传入新的数据包对数据包进行改写
+//   - 在 next 返回后执行的代码将在后续中间件及事件处理函数执行完成后执行，可用于统计耗时、捕获异常并回复错误等
+type PacketMiddleware func(srv *Server, conn *Conn, packet []byte, next func(packet []byte))
+
+// UsePacketMiddleware 按顺序添加数据包中间件，先添加的中间件将先执行
+//   - 中间件将在处理数据包的消息循环或分流通道中执行，与 ConnectionReceivePacketEvent 相同，对系统通道及分流通道均生效
+//   - 握手阶段的数据包将交由 WithHandshake 设置的处理函数处理，不会经过中间件
+//   - 应在服务器运行前添加
+func (slf *Server) UsePacketMiddleware(middlewares ...PacketMiddleware) {
+	slf.packetMiddlewares = append(slf.packetMiddlewares, middlewares...)
+}
+
+// handlePacket 通过中间件处理数据包
+func (slf *Server) handlePacket(index int, conn *Conn, packet []byte) {
+	if index >= len(slf.packetMiddlewares) {
+		if !slf.OnConnectionPacketPreprocessEvent(conn, packet, func(newPacket []byte) { packet = newPacket }) {
+			slf.OnConnectionReceivePacketEvent(conn, packet)
+		}
+		return
+	}
+	slf.packetMiddlewares[index](slf, conn, packet, func(packet []byte) {
+		slf.handlePacket(index+1, conn, packet)
+	})
+}
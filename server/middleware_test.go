@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServer_UsePacketMiddleware(t *testing.T) {
+	var l sync.Mutex
+	var trace []string
+	var record = func(s string) {
+		l.Lock()
+		trace = append(trace, s)
+		l.Unlock()
+	}
+	var done = make(chan struct{}, 4)
+	var started = make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithShunt(func(guid int64) chan *server.Message {
+		return make(chan *server.Message, 16)
+	}, func(conn *server.Conn) (guid int64, allowToCreate bool) {
+		_, allowToCreate = conn.GetData("shunt").(bool)
+		return 1, allowToCreate
+	}))
+	srv.UsePacketMiddleware(func(srv *server.Server, conn *server.Conn, packet []byte, next func(packet []byte)) {
+		record("outer:" + string(packet))
+		next([]byte(strings.ToUpper(string(packet))))
+		record("outer-after")
+		done <- struct{}{}
+	}, func(srv *server.Server, conn *server.Conn, packet []byte, next func(packet []byte)) {
+		if string(packet) == "DROP" {
+			return
+		}
+		next(packet)
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		record("handle:" + string(packet))
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	shunted := server.NewEmptyConn(srv)
+	shunted.SetData("shunt", true)
+	for _, c := range []struct {
+		conn   *server.Conn
+		packet string
+		expect []string
+	}{
+		{server.NewEmptyConn(srv), "a", []string{"outer:a", "handle:A", "outer-after"}},
+		{server.NewEmptyConn(srv), "drop", []string{"outer:drop", "outer-after"}},
+		{shunted, "b", []string{"outer:b", "handle:B", "outer-after"}},
+	} {
+		l.Lock()
+		trace = nil
+		l.Unlock()
+		server.PushPacketMessage(srv, c.conn, 0, []byte(c.packet))
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("middleware timeout")
+		}
+		l.Lock()
+		if strings.Join(trace, ",") != strings.Join(c.expect, ",") {
+			t.Fatal(trace)
+		}
+		l.Unlock()
+	}
+	if stats := srv.GetShuntStats(); len(stats) != 1 || stats[0].Processed != 1 {
+		t.Fatal(stats)
+	}
+}
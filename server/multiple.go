@@ -11,8 +11,9 @@ import (
 
 func NewMultipleServer(serverHandle ...func() (addr string, srv *Server)) *MultipleServer {
 	ms := &MultipleServer{
-		servers:   make([]*Server, len(serverHandle), len(serverHandle)),
-		addresses: make([]string, len(serverHandle), len(serverHandle)),
+		servers:      make([]*Server, len(serverHandle), len(serverHandle)),
+		addresses:    make([]string, len(serverHandle), len(serverHandle)),
+		systemSignal: make(chan os.Signal, 1),
 	}
 	for i := 0; i < len(serverHandle); i++ {
 		ms.addresses[i], ms.servers[i] = serverHandle[i]()
@@ -24,6 +25,7 @@ type MultipleServer struct {
 	servers          []*Server
 	addresses        []string
 	exitEventHandles []func()
+	systemSignal     chan os.Signal
 }
 
 func (slf *MultipleServer) Run() {
@@ -68,9 +70,22 @@ func (slf *MultipleServer) Run() {
 		)
 	}
 	log.Info("Server", log.String(serverMultipleMark, "===================================================================="))
+	notifyHotRestartReady()
 
-	systemSignal := make(chan os.Signal, 1)
-	signal.Notify(systemSignal, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(slf.systemSignal, append([]os.Signal{syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT}, hotRestartSignals...)...)
+	var systemSignal = make(chan os.Signal, 1)
+	go func() {
+		for sig := range slf.systemSignal {
+			if isHotRestartSignal(sig) {
+				if err := handoff(slf.servers...); err != nil {
+					log.Error("Server", log.String(serverMultipleMark, "HotRestart"), log.Err(err))
+					continue
+				}
+			}
+			systemSignal <- sig
+			return
+		}
+	}()
 	select {
 	case err := <-exceptionChannel:
 		for _, server := range slf.servers {
@@ -109,6 +124,12 @@ func (slf *MultipleServer) Run() {
 	slf.OnExitEvent()
 }
 
+// HotRestart 热重启所有服务器
+//   - 所有服务器均需要通过 WithHotRestart 创建，具体行为可参考 Server.HotRestart
+func (slf *MultipleServer) HotRestart() {
+	slf.systemSignal <- hotRestartSignal{}
+}
+
 // RegExitEvent 注册退出事件
 func (slf *MultipleServer) RegExitEvent(handle func()) {
 	slf.exitEventHandles = append(slf.exitEventHandles, handle)
@@ -20,11 +20,15 @@ const (
 	NetworkWebsocket Network = "websocket"
 	NetworkKcp       Network = "kcp"
 	NetworkGRPC      Network = "grpc"
+	// NetworkQUIC 该模式下每个 QUIC 连接将由客户端打开的首个双向流承载数据包，需要通过 WithTLS 设置证书，未设置时将使用临时生成的自签名证书
+	//  - 自签名证书仅适用于 RunModeDev 及 RunModeTest 模式，RunModeProd 模式下未设置证书时 Run 将返回 ErrQUICCertificateRequired
+	//  - 数据包默认通过 NewLengthFieldCodec 创建的编解码器进行封包及分包，可通过 WithPacketCodec 替换
+	NetworkQUIC Network = "quic"
 )
 
 var (
 	networks = []Network{
-		NetworkNone, NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkHttp, NetworkWebsocket, NetworkKcp, NetworkGRPC,
+		NetworkNone, NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkHttp, NetworkWebsocket, NetworkKcp, NetworkGRPC, NetworkQUIC,
 	}
 )
 
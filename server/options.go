@@ -2,9 +2,12 @@ package server
 
 import (
 	"github.com/gin-contrib/pprof"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/utils/concurrent"
+	"github.com/kercylan98/minotaur/utils/crypto"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/timer"
+	"github.com/quic-go/quic-go"
 	"google.golang.org/grpc"
 	"reflect"
 	"time"
@@ -32,7 +35,7 @@ type option struct {
 type runtime struct {
 	id                        int64            // 服务器id
 	cross                     map[string]Cross // 跨服
-	deadlockDetect            time.Duration    // 是否开启死锁检测
+	deadlockDetect            *deadlockDetect  // 死锁检测
 	supportMessageTypes       map[int]bool     // websocket模式下支持的消息类型
 	certFile, keyFile         string           // TLS文件
 	messagePoolSize           int              // 消息池大小
@@ -41,6 +44,24 @@ type runtime struct {
 	websocketReadDeadline     time.Duration    // websocket连接超时时间
 	websocketCompression      int              // websocket压缩等级
 	websocketWriteCompression bool             // websocket写入压缩
+	websocketEndpoint         *wsEndpoint      // websocket升级路径及升级器
+	packetCodec               Codec            // 数据包编解码器
+	connRateLimit             *rateLimit       // 连接限流
+	connLimitPerIP            int              // 单个IP的连接数限制
+	idle                      *idleDetect      // 空闲连接检测
+	writeQueue                *writeQueue      // 连接写入队列
+	metrics                   *metrics         // 服务器指标
+	maintenance               *maintenance     // 维护模式及停机排空
+	hotRestart                *hotRestart      // 热重启
+	handshakeConfig           *handshakeConfig // 连接握手
+	recorder                  *recorder        // 数据包录制
+	shuntIdle                 time.Duration    // 分流通道空闲释放时间
+	secureConfig              *secureConfig    // 加密传输
+	kcpOptions                *KCPOptions      // KCP 会话参数
+	quicConfig                *quic.Config     // QUIC 配置
+	gnetOptions               []GNetOption     // gnet 引擎可选项
+	udpSessionIdle            time.Duration    // UDP 会话空闲过期时间
+	udpSessionMax             int              // UDP 会话数量上限
 }
 
 // WithWebsocketWriteCompression 通过数据写入压缩的方式创建Websocket服务器
@@ -54,6 +75,77 @@ func WithWebsocketWriteCompression() Option {
 	}
 }
 
+// WithWebsocketPaths 通过额外的升级路径创建Websocket服务器
+//   - 除 Run 函数中地址所指定的路径外，对 paths 中路径的请求也将升级为 Websocket 连接
+//   - 可通过 Conn.GetWebsocketRequest 获取连接升级时请求的路径
+func WithWebsocketPaths(paths ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket {
+			return
+		}
+		if srv.websocketEndpoint == nil {
+			srv.websocketEndpoint = &wsEndpoint{}
+		}
+		srv.websocketEndpoint.paths = append(srv.websocketEndpoint.paths, paths...)
+	}
+}
+
+// WithWebsocketUpgrader 通过特定的升级器创建Websocket服务器
+//   - 可用于自定义跨域检查、子协议、读写缓冲区大小等
+//   - 默认的升级器读写缓冲区大小为 4096，且允许所有来源的请求
+func WithWebsocketUpgrader(upgrader *websocket.Upgrader) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket || upgrader == nil {
+			return
+		}
+		if srv.websocketEndpoint == nil {
+			srv.websocketEndpoint = &wsEndpoint{}
+		}
+		srv.websocketEndpoint.upgrader = upgrader
+	}
+}
+
+// WithWebsocketAuthenticator 通过升级前鉴权的方式创建Websocket服务器
+//   - 在升级为 Websocket 连接前将调用 authenticator 对请求进行鉴权，返回错误时将以返回的状态码拒绝升级
+//   - 可通过 Conn.GetWebsocketRequest 获取升级时的请求
+func WithWebsocketAuthenticator(authenticator WebsocketUpgradeAuthenticator) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket || authenticator == nil {
+			return
+		}
+		if srv.websocketEndpoint == nil {
+			srv.websocketEndpoint = &wsEndpoint{}
+		}
+		srv.websocketEndpoint.authenticator = authenticator
+	}
+}
+
+// WithHandshake 通过连接握手的方式创建服务器
+//   - 新的连接将首先进入握手阶段，握手阶段接收到的前 packets 个数据包将交由 handle 处理，而不会触发 ConnectionReceivePacketEvent
+//   - 当 handle 返回 pass 为 true 时连接才会被视为打开，并触发 ConnectionOpenedEvent
+//   - 当 handle 返回错误、接收 packets 个数据包后仍未通过或超过 timeout 仍未通过时，连接将被关闭并触发 ConnectionHandshakeFailedEvent
+//   - packets 小于等于 0 时将默认为 1，timeout 小于等于 0 时将使用 DefaultHandshakeTimeout
+//   - 握手阶段的连接不会被视为在线连接，也不会进行空闲检测，因此握手时间始终受到限制
+//   - 支持：Tcp、Udp、Unix、Kcp、Websocket、QUIC
+func WithHandshake(packets int, timeout time.Duration, handle HandshakeHandle) Option {
+	return func(srv *Server) {
+		switch srv.network {
+		case NetworkHttp, NetworkGRPC, NetworkNone:
+			return
+		}
+		if handle == nil {
+			return
+		}
+		if packets <= 0 {
+			packets = 1
+		}
+		if timeout <= 0 {
+			timeout = DefaultHandshakeTimeout
+		}
+		srv.handshakeConfig = &handshakeConfig{packets: packets, timeout: timeout, handle: handle}
+	}
+}
+
 // WithWebsocketCompression 通过数据压缩的方式创建Websocket服务器
 //   - 默认不开启数据压缩
 func WithWebsocketCompression(level int) Option {
@@ -80,13 +172,179 @@ func WithMessageChannelSize(size int) Option {
 	}
 }
 
+// WithPacketCodec 通过特定的数据包编解码器创建服务器
+//   - 在读取数据时将通过编解码器进行分包，确保 ConnectionReceivePacketEvent 每次仅接收到一个完整的数据包
+//   - 在通过 Conn.Write 写入数据时将通过编解码器进行封包
+//   - 支持：Tcp、Udp、Unix、Kcp、QUIC
+//   - 默认不使用编解码器，可使用内置的 NewLengthFieldCodec 函数创建基于长度字段的编解码器
+//   - QUIC 的默认双向流为字节流，未设置编解码器时将默认使用 NewLengthFieldCodec 创建的编解码器
+func WithPacketCodec(codec Codec) Option {
+	return func(srv *Server) {
+		switch srv.network {
+		case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkKcp, NetworkQUIC:
+			srv.packetCodec = codec
+		}
+	}
+}
+
+// WithConnectionRateLimit 通过对连接接收数据包进行限流的方式创建服务器
+//   - packetsPerSecond：每个连接每秒允许接收的数据包数量，<= 0 时表示不限制
+//   - bytesPerSecond：每个连接每秒允许接收的字节数，<= 0 时表示不限制，大于该值的单个数据包将无法通过限流
+//   - action：超出限制后的操作，超出限制时将触发 ConnectionRateLimitedEvent
+//
+// 注意事项：
+//   - 在 Tcp、Udp、Unix 网络中，RateLimitActionDelay 将阻塞连接所在的事件循环，推荐仅在 Websocket、Kcp 中使用
+func WithConnectionRateLimit(packetsPerSecond, bytesPerSecond int, action RateLimitAction) Option {
+	return func(srv *Server) {
+		if packetsPerSecond <= 0 && bytesPerSecond <= 0 {
+			return
+		}
+		if _, exist := rateLimitActionNames[action]; !exist {
+			action = RateLimitActionDrop
+		}
+		srv.connRateLimit = &rateLimit{
+			packets: packetsPerSecond,
+			bytes:   bytesPerSecond,
+			action:  action,
+		}
+	}
+}
+
+// WithConnectionLimitPerIP 通过限制单个 IP 连接数的方式创建服务器
+//   - 超出限制的新连接将被直接拒绝，不会触发 ConnectionOpenedEvent，但会触发 ConnectionRateLimitedEvent
+//   - 支持：Tcp、Unix、Websocket、Kcp
+func WithConnectionLimitPerIP(limit int) Option {
+	return func(srv *Server) {
+		srv.connLimitPerIP = limit
+	}
+}
+
+// WithConnectionIdleTimeout 通过关闭空闲连接的方式创建服务器
+//   - 当连接超过 timeout 未接收到任何数据时，连接将被关闭，ConnectionClosedEvent 中的错误为 ErrConnectionIdleTimeout
+//   - 适用于所有网络类型，可配合 WithConnectionPing 使用应用层心跳保持连接
+//   - 当 timeout <= 0 时表示不检测
+func WithConnectionIdleTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		if srv.idle == nil {
+			srv.idle = &idleDetect{}
+		}
+		srv.idle.timeout = timeout
+	}
+}
+
+// WithConnectionPing 通过向空闲连接发送应用层心跳数据包的方式创建服务器
+//   - 当连接超过 interval 未接收到任何数据时，将通过 packet 函数生成心跳数据包并写入连接，之后每隔 interval 发送一次，直到连接接收到数据
+//   - 当 interval <= 0 或 packet 为 nil 时表示不发送心跳数据包
+func WithConnectionPing(interval time.Duration, packet func(conn *Conn) []byte) Option {
+	return func(srv *Server) {
+		if interval <= 0 || packet == nil {
+			return
+		}
+		if srv.idle == nil {
+			srv.idle = &idleDetect{}
+		}
+		srv.idle.interval = interval
+		srv.idle.ping = packet
+	}
+}
+
+// WithConnectionWriteQueue 通过限制连接写入队列的方式创建服务器
+//   - size：每个连接写入队列可容纳的数据包数量，<= 0 时将使用 DefaultConnWriteQueueSize
+//   - maxPendingBytes：每个连接写入队列可容纳的字节数，<= 0 时表示不限制
+//   - policy：写入队列已满时的策略，写入队列已满时将触发 ConnectionSlowEvent
+//   - timeout：policy 为 WriteQueuePolicyBlock 时阻塞写入的超时时间，<= 0 时表示一直阻塞直到队列可用或连接关闭
+//
+// 注意事项：
+//   - 默认情况下写入队列可容纳 DefaultConnWriteQueueSize 个数据包，已满时将一直阻塞，这可能会使得缓慢的连接阻塞整个服务器
+//   - 未能写入队列的数据包将被丢弃，并在 Conn.Write 的回调函数中返回 ErrConnectionWriteQueueFull
+func WithConnectionWriteQueue(size int, maxPendingBytes int64, policy WriteQueuePolicy, timeout time.Duration) Option {
+	return func(srv *Server) {
+		if size <= 0 {
+			size = DefaultConnWriteQueueSize
+		}
+		if _, exist := writeQueuePolicyNames[policy]; !exist {
+			policy = WriteQueuePolicyBlock
+		}
+		srv.writeQueue = &writeQueue{
+			size:    size,
+			bytes:   maxPendingBytes,
+			policy:  policy,
+			timeout: timeout,
+		}
+	}
+}
+
+// WithMetrics 通过收集服务器指标的方式创建服务器
+//   - 将记录各消息类型的数量及执行耗时、连接收发的字节数及数据包数量、连接打开及关闭的数量等指标
+//   - addr：独立侦听的地址，例如 ":9100"，适用于 Tcp、Kcp 等无法直接提供 HTTP 服务的网络，为空时不进行独立侦听
+//   - pattern：指标的路由，默认为 DefaultMetricsPattern
+//
+// 指标将以 Prometheus 文本格式输出，在未独立侦听时，可通过 Server.MetricsHandler 将其注册到其他 HTTP 服务器中
+func WithMetrics(addr string, pattern ...string) Option {
+	return func(srv *Server) {
+		var p = DefaultMetricsPattern
+		if len(pattern) > 0 && len(pattern[0]) > 0 {
+			p = pattern[0]
+		}
+		srv.metrics = newMetrics(addr, p)
+	}
+}
+
+// WithMaintenancePacket 通过在维护模式或停机期间向新连接发送维护数据包的方式创建服务器
+//   - 当服务器处于维护模式（Server.EnterMaintenance）或正在停机时，新的连接将被拒绝，在关闭前将向其发送 packet
+//   - 在非 Websocket 网络中，packet 将经过 WithPacketCodec 设置的编解码器封包
+//   - 未设置时新的连接将被直接关闭，Websocket 连接将收到 503 响应
+func WithMaintenancePacket(packet []byte) Option {
+	return func(srv *Server) {
+		if srv.maintenance == nil {
+			srv.maintenance = &maintenance{}
+		}
+		srv.maintenance.packet = packet
+	}
+}
+
+// WithShutdownDrain 通过在停机前排空服务器的方式创建服务器
+//   - 停机时将拒绝新的连接，并通过 notify 函数生成通知数据包写入所有已连接的客户端，当 notify 为 nil 或返回空数据包时不进行通知
+//   - 随后将最多等待 timeout 时间，直到服务器中的消息（包括异步消息）处理完成且所有连接的写入队列清空
+//   - 默认情况下将一直等待消息处理完成，不会通知客户端
+func WithShutdownDrain(timeout time.Duration, notify func(conn *Conn) []byte) Option {
+	return func(srv *Server) {
+		if srv.maintenance == nil {
+			srv.maintenance = &maintenance{}
+		}
+		srv.maintenance.timeout = timeout
+		srv.maintenance.notify = notify
+	}
+}
+
+// WithHotRestart 通过支持热重启的方式创建服务器
+//   - 通过 Server.HotRestart、MultipleServer.HotRestart 或向进程发送 SIGUSR2 信号触发热重启，仅支持 Linux 系统
+//   - 热重启时将以相同的参数启动新的进程，并将侦听器交接给新的进程，当前进程在新进程启动完成后停止接受新的连接，并最多等待 drain 时间直到已有连接全部断开，之后正常停止服务器
+//   - 支持：Tcp、Tcp4、Tcp6、Websocket、Http、GRPC
+//
+// 注意事项：
+//   - 侦听器将通过继承文件描述符的方式交接，新旧进程间不会出现无法连接的情况
+//   - gnet 无法在保留已有连接的同时单独停止侦听，因此启用热重启后 Tcp 网络将基于 net.Listener 运行，WithGNetOptions 设置的可选项将不再生效
+//   - Udp、Unix 网络依旧基于 gnet 运行，不支持热重启，触发时将返回 ErrHotRestartNotSupportNetwork
+func WithHotRestart(drain time.Duration) Option {
+	return func(srv *Server) {
+		srv.hotRestart = &hotRestart{drain: drain}
+	}
+}
+
 // WithDeadlockDetect 通过死锁、死循环、永久阻塞检测的方式创建服务器
-//   - 当检测到死锁、死循环、永久阻塞时，服务器将会生成 WARN 类型的日志，关键字为 "SuspectedDeadlock"
+//   - 当检测到死锁、死循环、永久阻塞时，服务器将会生成 WARN 类型的日志，关键字为 "SuspectedDeadlock"，其中包含执行该消息的协程堆栈，并触发 SuspectedDeadlockEvent
+//   - 当疑似死锁的消息最终执行完成时，将再次生成日志并触发 SuspectedDeadlockEvent 报告消息执行的总时长
+//   - 可通过 options 为特定消息类型或路由设置独立的阈值，或在检测到疑似死锁时采集所有协程的堆栈
+//   - 所有消息共用同一个检测协程，不会为每个消息创建额外的协程
 //   - 默认不开启死锁检测
-func WithDeadlockDetect(t time.Duration) Option {
+func WithDeadlockDetect(t time.Duration, options ...DeadlockDetectOption) Option {
 	return func(srv *Server) {
 		if t > 0 {
-			srv.deadlockDetect = t
+			srv.deadlockDetect = newDeadlockDetect(t)
+			for _, option := range options {
+				option(srv.deadlockDetect)
+			}
 			log.Info("DeadlockDetect", log.String("Time", t.String()))
 		}
 	}
@@ -151,6 +409,11 @@ func WithCross(crossName string, serverId int64, cross Cross) Option {
 				msg.t = MessageTypeCross
 				msg.attrs = []any{serverId, packet}
 				srv.pushMessage(msg)
+			}, func(serverId int64, packet []byte, reply func(packet []byte) error) {
+				msg := srv.messagePool.Get()
+				msg.t = MessageTypeCrossRequest
+				msg.attrs = []any{serverId, packet, reply}
+				srv.pushMessage(msg)
 			})
 			if err != nil {
 				log.Info("Cross", log.Int64("ServerID", serverId), log.String("Cross", reflect.TypeOf(cross).String()), log.String("State", "WaitNatsRun"))
@@ -163,11 +426,12 @@ func WithCross(crossName string, serverId int64, cross Cross) Option {
 }
 
 // WithTLS 通过安全传输层协议TLS创建服务器
-//   - 支持：Http、Websocket
+//   - QUIC 在 RunModeProd 模式下必须设置证书，否则 Run 将返回 ErrQUICCertificateRequired
+//   - 支持：Http、Websocket、QUIC
 func WithTLS(certFile, keyFile string) Option {
 	return func(srv *Server) {
 		switch srv.network {
-		case NetworkHttp, NetworkWebsocket:
+		case NetworkHttp, NetworkWebsocket, NetworkQUIC:
 			srv.certFile = certFile
 			srv.keyFile = keyFile
 		}
@@ -240,15 +504,128 @@ func WithPProf(pattern ...string) Option {
 //   - MessageTypePacket
 //
 // 注意事项：
-//   - 需要在分流通道使用完成后主动调用 Server.ShuntChannelFreed 函数释放分流通道，避免内存泄漏
+//   - 需要在分流通道使用完成后主动调用 Server.ShuntChannelFreed 函数释放分流通道，避免内存泄漏，也可以通过 WithShuntIdleTimeout 自动释放空闲的分流通道
+//   - 可以通过 Server.ShuntRebind 将连接绑定到其他分流通道，通过 Server.GetShuntStats 获取各分流通道的统计信息
 func WithShunt(channelGenerator func(guid int64) chan *Message, shuntMatcher func(conn *Conn) (guid int64, allowToCreate bool)) Option {
 	return func(srv *Server) {
 		if channelGenerator == nil || shuntMatcher == nil {
 			log.Warn("WithShunt", log.String("State", "Ignore"), log.String("Reason", "channelGenerator or shuntMatcher is nil"))
 			return
 		}
-		srv.shuntChannels = concurrent.NewBalanceMap[int64, chan *Message]()
+		srv.shuntChannels = concurrent.NewBalanceMap[int64, *shunt]()
 		srv.channelGenerator = channelGenerator
 		srv.shuntMatcher = shuntMatcher
 	}
 }
+
+// WithShuntIdleTimeout 通过自动释放空闲分流通道的方式创建服务器
+//   - 当分流通道中没有等待处理的消息，且超过 timeout 时间没有处理消息时，将自动释放该分流通道并触发 ShuntChannelClosedEvent
+//   - 被释放的分流通道再次被匹配时将重新创建
+//   - 需要配合 WithShunt 使用
+func WithShuntIdleTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		srv.shuntIdle = timeout
+	}
+}
+
+// WithPacketRecorder 通过录制数据包的方式创建服务器
+//   - 将以 JSON Lines 格式将连接的打开、关闭以及接收和写入的数据包按时间顺序录制到 dir 目录下的文件中，可通过 replay 包进行回放
+//   - 当录制文件超过 maxFileSize 字节时将创建新的录制文件，并仅保留最新的 maxFiles 个文件，小于等于 0 时将分别使用 DefaultRecorderFileSize 及 DefaultRecorderFileCount
+//   - 当设置了 filter 时，仅录制 filter 返回 true 的连接，可用于仅录制特定的连接
+//   - 录制将在独立的协程中写入文件，当缓冲区已满时新的记录将被丢弃，并在服务器停止时输出丢弃数量
+func WithPacketRecorder(dir string, maxFileSize int64, maxFiles int, filter ...func(conn *Conn) bool) Option {
+	return func(srv *Server) {
+		if maxFileSize <= 0 {
+			maxFileSize = DefaultRecorderFileSize
+		}
+		if maxFiles <= 0 {
+			maxFiles = DefaultRecorderFileCount
+		}
+		srv.recorder = &recorder{dir: dir, size: maxFileSize, count: maxFiles}
+		if len(filter) > 0 {
+			srv.recorder.filter = filter[0]
+		}
+	}
+}
+
+// WithSecureTransport 通过加密传输的方式创建服务器
+//   - 新的连接将首先通过 X25519 进行密钥交换，随后接收和写入的数据包均将通过 cipher 加密，并携带递增的序列号以防止重放
+//   - 未设置 psk 时密钥交换未经认证，仅能防止被动窃听，无法抵御中间人攻击
+//   - 设置 psk 后其将参与密钥派生，客户端需要通过 client.Client.EnableSecure 设置相同的 psk，未持有相同 psk 的一方发送的数据包将无法通过认证并导致连接关闭
+//   - 数据包的加解密对 ConnectionReceivePacketEvent 及 Conn.Write 透明，密钥交换完成后才会进入 WithHandshake 的握手阶段或触发 ConnectionOpenedEvent
+//   - 当发送的数据包数量达到 rotatePackets 或距上次轮换超过 rotateInterval 时将轮换密钥，为 0 时表示不限制
+//   - 未能在 timeout 时间内完成密钥交换的连接将被关闭并触发 ConnectionHandshakeFailedEvent，小于等于 0 时将使用 DefaultSecureHandshakeTimeout
+//   - 客户端需要通过 client.Client.EnableSecure 开启加密传输
+//   - Udp 将使用滑动窗口校验序列号，允许数据报丢失或乱序到达，无法解密或重复的数据报将被丢弃而不会关闭连接
+//   - 支持：Tcp、Udp、Unix、Kcp、Websocket、QUIC，其中 Tcp、Unix 及 QUIC 需要配合 WithPacketCodec 使用以确保数据包边界
+func WithSecureTransport(cipher crypto.SecureCipher, rotatePackets uint64, rotateInterval, timeout time.Duration, psk ...[]byte) Option {
+	return func(srv *Server) {
+		switch srv.network {
+		case NetworkHttp, NetworkGRPC, NetworkNone:
+			return
+		}
+		if timeout <= 0 {
+			timeout = DefaultSecureHandshakeTimeout
+		}
+		srv.secureConfig = &secureConfig{cipher: cipher, rotatePackets: rotatePackets, rotateInterval: rotateInterval, timeout: timeout}
+		if len(psk) > 0 {
+			srv.secureConfig.psk = psk[0]
+		}
+	}
+}
+
+// WithKCPOptions 通过特定的 KCP 会话参数创建服务器
+//   - 可通过 NewKCPOptions 基于 KCPPresetNormal、KCPPresetFast 或 KCPPresetTurbo 预设创建参数，并根据需要开启前向纠错及数据包加密
+//   - 客户端需要通过 client.NewKCP 使用相同的 Block、DataShards 及 ParityShards 进行连接
+//   - 默认不进行任何调整，使用 kcp-go 的默认参数
+//   - 可通过 Conn.GetKCPStats 获取单个连接的往返时间等统计，kcp-go 未提供单个会话的重传计数，重传、丢包等计数需通过 GetKCPSnmp 获取进程内所有 KCP 会话的累计值
+//   - 支持：Kcp
+func WithKCPOptions(options *KCPOptions) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkKcp {
+			return
+		}
+		srv.kcpOptions = options
+	}
+}
+
+// WithQUICConfig 通过特定的 QUIC 配置创建服务器
+//   - 可用于调整空闲超时、保活间隔及流控窗口等参数，默认使用 quic-go 的默认配置
+//   - 支持：QUIC
+func WithQUICConfig(config *quic.Config) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkQUIC {
+			return
+		}
+		srv.quicConfig = config
+	}
+}
+
+// WithGNetOptions 通过特定的 gnet 引擎可选项创建服务器
+//   - 可用于调整事件循环数量、负载均衡策略、读取缓冲区容量、SO_REUSEPORT 及 TCP 保活等参数，以适应大量并发连接的场景
+//   - 服务器的周期性维护任务依赖 gnet 的定时器，因此定时器将始终开启
+//   - 支持：Tcp、Udp、Unix，通过 WithHotRestart 创建的 Tcp 服务器不基于 gnet 运行，设置的可选项将不会生效
+func WithGNetOptions(options ...GNetOption) Option {
+	return func(srv *Server) {
+		if !isGNetNetwork(srv.network) {
+			return
+		}
+		srv.gnetOptions = append(srv.gnetOptions, options...)
+	}
+}
+
+// WithUDPSessionIdleTimeout 通过特定的 UDP 会话空闲过期时间创建服务器
+//   - UDP 模式下服务器将根据远程地址维护虚拟会话，首个数据报到达时创建会话并触发 ConnectionOpenedEvent
+//   - 当会话超过 timeout 未接收到任何数据报时将被关闭，ConnectionClosedEvent 中的错误为 ErrUDPSessionExpired
+//   - timeout 小于等于 0 时将使用 DefaultUDPSessionIdleTimeout
+//   - 会话数量达到 maxSessions 时来自新地址的数据报将被丢弃，避免伪造源地址的数据报无限制地创建会话，小于等于 0 时将使用 DefaultUDPMaxSessions
+//   - 支持：Udp
+func WithUDPSessionIdleTimeout(timeout time.Duration, maxSessions int) Option {
+	return func(srv *Server) {
+		if !isUDPNetwork(srv.network) {
+			return
+		}
+		srv.udpSessionIdle = timeout
+		srv.udpSessionMax = maxSessions
+	}
+}
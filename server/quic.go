@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/quic-go/quic-go"
+	"math/big"
+	"time"
+)
+
+const (
+	QUICProtocol                = "minotaur"       // QUIC 连接使用的 ALPN 协议，客户端需在 tls.Config.NextProtos 中指定
+	QUICStreamPreamble     byte = 1                // 客户端打开默认双向流后需要首先发送的字节，用于使服务器感知到该流
+	DefaultQUICOpenTimeout      = 10 * time.Second // 默认等待客户端打开默认双向流的超时时间
+
+	quicCloseNormal  quic.ApplicationErrorCode = 0 // 正常关闭
+	quicCloseRefused quic.ApplicationErrorCode = 1 // 拒绝连接
+)
+
+// listenQUIC 侦听 QUIC 连接，未通过 WithTLS 设置证书时将使用临时生成的自签名证书
+//   - 自签名证书仅用于开发及测试，RunModeProd 模式下未设置证书时将返回 ErrQUICCertificateRequired
+func (slf *Server) listenQUIC() (*quic.Listener, error) {
+	var certificate tls.Certificate
+	var err error
+	if len(slf.certFile)+len(slf.keyFile) > 0 {
+		certificate, err = tls.LoadX509KeyPair(slf.certFile, slf.keyFile)
+	} else if slf.runMode == RunModeProd {
+		return nil, ErrQUICCertificateRequired
+	} else {
+		log.Warn("Server", log.String("network", string(slf.network)), log.String("TLS", "no certificate specified, using a self-signed certificate"))
+		certificate, err = selfSignedCertificate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quic.ListenAddr(slf.addr, &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		NextProtos:   []string{QUICProtocol},
+	}, slf.quicConfig)
+}
+
+// serveQUIC 接受 QUIC 连接，直到侦听器被关闭
+func (slf *Server) serveQUIC(listener *quic.Listener) {
+	for {
+		qc, err := listener.Accept(context.Background())
+		if err != nil {
+			if slf.isShutdown.Load() || errors.Is(err, quic.ErrServerClosed) {
+				return
+			}
+			continue
+		}
+		go slf.acceptQUIC(qc)
+	}
+}
+
+// acceptQUIC 等待客户端打开默认双向流，并将其作为连接打开
+func (slf *Server) acceptQUIC(qc quic.Connection) {
+	ctx, cancel := context.WithTimeout(qc.Context(), DefaultQUICOpenTimeout)
+	stream, err := qc.AcceptStream(ctx)
+	cancel()
+	if err != nil {
+		_ = qc.CloseWithError(quicCloseRefused, err.Error())
+		return
+	}
+	var preamble [1]byte
+	_ = stream.SetReadDeadline(time.Now().Add(DefaultQUICOpenTimeout))
+	if _, err = stream.Read(preamble[:]); err != nil || preamble[0] != QUICStreamPreamble {
+		_ = qc.CloseWithError(quicCloseRefused, "invalid stream preamble")
+		return
+	}
+	_ = stream.SetReadDeadline(time.Time{})
+
+	if packet, refused := slf.refuse(); refused {
+		if len(packet) > 0 {
+			_, _ = stream.Write(packet)
+			_ = stream.Close()
+		}
+		_ = qc.CloseWithError(quicCloseRefused, "")
+		return
+	}
+	if !slf.acquireIP(ipOf(qc.RemoteAddr())) {
+		_ = qc.CloseWithError(quicCloseRefused, "")
+		return
+	}
+	conn := newQUICConn(slf, qc, stream)
+	slf.openConn(conn, func() {
+		slf.OnConnectionOpenedEvent(conn)
+		slf.OnConnectionOpenedAfterEvent(conn)
+	})
+
+	defer func() {
+		if err := recover(); err != nil {
+			e, ok := err.(error)
+			if !ok {
+				e = fmt.Errorf("%v", err)
+			}
+			conn.Close(e)
+		}
+	}()
+	buf := make([]byte, 4096)
+	for !conn.IsClosed() {
+		n, err := stream.Read(buf)
+		if err != nil {
+			if conn.IsClosed() || slf.isShutdown.Load() {
+				break
+			}
+			panic(err)
+		}
+		if err = conn.receive(0, buf[:n]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// selfSignedCertificate 生成临时的自签名证书
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"minotaur"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
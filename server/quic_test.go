@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"testing"
+	"time"
+)
+
+func TestNetworkQUIC(t *testing.T) {
+	var closed = make(chan struct{}, 1)
+	srv := server.New(server.NetworkQUIC)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if conn.IsEmpty() {
+			t.Error("quic connection is empty")
+		}
+		conn.Write(packet)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- struct{}{}
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9986"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	var received = make(chan []byte, 3)
+	cli := client.NewQUIC("127.0.0.1:9986", &tls.Config{InsecureSkipVerify: true})
+	cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		received <- packet
+	})
+	if err := cli.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// 默认使用长度字段编解码器，连续写入的数据包不会被合并
+	var packets = []string{"hello", "minotaur", string(make([]byte, 8192))}
+	for _, packet := range packets {
+		cli.Write([]byte(packet))
+	}
+	for _, expect := range packets {
+		select {
+		case echo := <-received:
+			if string(echo) != expect {
+				t.Fatal(len(echo), len(expect))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("echo timeout")
+		}
+	}
+
+	cli.Close()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close timeout")
+	}
+}
+
+func TestNetworkQUIC_ProdCertificate(t *testing.T) {
+	srv := server.New(server.NetworkQUIC, server.WithRunMode(server.RunModeProd))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+	if err := srv.Run(":9986"); !errors.Is(err, server.ErrQUICCertificateRequired) {
+		t.Fatal(err)
+	}
+}
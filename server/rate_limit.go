@@ -0,0 +1,131 @@
+package server
+
+import (
+	"golang.org/x/time/rate"
+	"time"
+)
+
+const (
+	RateLimitActionDrop  RateLimitAction = iota + 1 // 限流操作：丢弃超出限制的数据包
+	RateLimitActionDelay                            // 限流操作：延迟处理超出限制的数据包，直到满足限制
+	RateLimitActionClose                            // 限流操作：关闭超出限制的连接
+)
+
+var rateLimitActionNames = map[RateLimitAction]string{
+	RateLimitActionDrop:  "Drop",
+	RateLimitActionDelay: "Delay",
+	RateLimitActionClose: "Close",
+}
+
+// RateLimitAction 连接超出限流后的操作
+type RateLimitAction byte
+
+func (slf RateLimitAction) String() string {
+	return rateLimitActionNames[slf]
+}
+
+// rateLimit 连接限流配置
+type rateLimit struct {
+	packets int             // 每秒允许接收的数据包数量
+	bytes   int             // 每秒允许接收的字节数
+	action  RateLimitAction // 超出限制后的操作
+}
+
+// initRateLimit 根据服务器的限流配置初始化连接的限流器
+func (slf *Conn) initRateLimit() {
+	limit := slf.server.connRateLimit
+	if limit == nil {
+		return
+	}
+	if limit.packets > 0 {
+		slf.packetLimiter = rate.NewLimiter(rate.Limit(limit.packets), limit.packets)
+	}
+	if limit.bytes > 0 {
+		slf.byteLimiter = rate.NewLimiter(rate.Limit(limit.bytes), limit.bytes)
+	}
+}
+
+// allow 检查连接是否允许接收特定大小的数据包，在超出限制时将根据限流配置执行对应的操作
+func (slf *Conn) allow(size int) bool {
+	limit := slf.server.connRateLimit
+	if limit == nil || (slf.packetLimiter == nil && slf.byteLimiter == nil) {
+		return true
+	}
+	var (
+		now          = time.Now()
+		ok           = true
+		delay        time.Duration
+		limiters     = [2]*rate.Limiter{slf.packetLimiter, slf.byteLimiter}
+		tokens       = [2]int{1, size}
+		reservations = make([]*rate.Reservation, 0, len(limiters))
+	)
+	for i, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		r := limiter.ReserveN(now, tokens[i])
+		if !r.OK() {
+			ok = false
+			break
+		}
+		reservations = append(reservations, r)
+		delay = max(delay, r.DelayFrom(now))
+	}
+	if ok && delay == 0 {
+		return true
+	}
+
+	action := limit.action
+	if !ok && action == RateLimitActionDelay {
+		action = RateLimitActionDrop
+	}
+	if action != RateLimitActionDelay {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	if slf.limitedAt.IsZero() || now.Sub(slf.limitedAt) >= time.Second {
+		slf.limitedAt = now
+		slf.server.OnConnectionRateLimitedEvent(slf.ip, slf, action, ErrConnectionRateLimited)
+	}
+	switch action {
+	case RateLimitActionDelay:
+		time.Sleep(delay)
+		return true
+	case RateLimitActionClose:
+		slf.Close(ErrConnectionRateLimited)
+	}
+	return false
+}
+
+// acquireIP 尝试占用特定 IP 的连接数，当超出单个 IP 的连接数限制时返回 false
+func (slf *Server) acquireIP(ip string) bool {
+	if slf.connLimitPerIP <= 0 {
+		return true
+	}
+	slf.ipConnectionsL.Lock()
+	defer slf.ipConnectionsL.Unlock()
+	if slf.ipConnections == nil {
+		slf.ipConnections = map[string]int{}
+	}
+	if slf.ipConnections[ip] >= slf.connLimitPerIP {
+		slf.OnConnectionRateLimitedEvent(ip, nil, RateLimitActionClose, ErrConnectionLimitPerIP)
+		return false
+	}
+	slf.ipConnections[ip]++
+	return true
+}
+
+// releaseIP 释放特定 IP 的连接数
+func (slf *Server) releaseIP(ip string) {
+	if slf.connLimitPerIP <= 0 {
+		return
+	}
+	slf.ipConnectionsL.Lock()
+	defer slf.ipConnectionsL.Unlock()
+	if n := slf.ipConnections[ip] - 1; n > 0 {
+		slf.ipConnections[ip] = n
+	} else {
+		delete(slf.ipConnections, ip)
+	}
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultRecorderFileSize   = 64 * 1024 * 1024 // 数据包录制文件的默认最大字节数
+	DefaultRecorderFileCount  = 10               // 默认保留的数据包录制文件数量
+	DefaultRecorderBufferSize = 1024 * 4         // 数据包录制缓冲区的默认大小
+
+	recorderFilePrefix = "packets-"
+	recorderFileSuffix = ".jsonl"
+)
+
+// PacketRecordDirection 数据包录制记录的类型
+type PacketRecordDirection string
+
+const (
+	PacketRecordInbound  PacketRecordDirection = "in"    // 接收到的数据包
+	PacketRecordOutbound PacketRecordDirection = "out"   // 写入的数据包
+	PacketRecordOpened   PacketRecordDirection = "open"  // 连接打开
+	PacketRecordClosed   PacketRecordDirection = "close" // 连接关闭
+)
+
+// PacketRecord 数据包录制记录，录制文件中每行为一条 JSON 格式的记录
+type PacketRecord struct {
+	Time      int64                 `json:"time"`             // 记录时间，Unix 纳秒时间戳
+	Conn      string                `json:"conn"`             // 连接ID
+	Direction PacketRecordDirection `json:"direction"`        // 记录类型
+	WST       int                   `json:"wst,omitempty"`    // Websocket 消息类型
+	Packet    []byte                `json:"packet,omitempty"` // 数据包
+}
+
+// recorder 数据包录制器
+//   - 所有函数均允许在 nil 上调用，此时将不进行任何记录
+type recorder struct {
+	dir     string                // 录制文件所在目录
+	size    int64                 // 单个录制文件的最大字节数
+	count   int                   // 保留的录制文件数量
+	filter  func(conn *Conn) bool // 连接过滤器
+	records chan *PacketRecord    // 等待写入的记录
+	done    chan struct{}         // 写入协程结束信号
+	file    *os.File              // 当前录制文件
+	writer  *bufio.Writer         // 当前录制文件的写入器
+	written int64                 // 当前录制文件已写入的字节数
+	dropped atomic.Int64          // 因缓冲区已满而丢弃的记录数量
+	rw      sync.RWMutex          // 运行状态锁
+	running bool                  // 是否正在运行
+}
+
+// record 记录连接的数据包或状态变化
+func (slf *recorder) record(conn *Conn, direction PacketRecordDirection, wst int, packet []byte) {
+	if slf == nil {
+		return
+	}
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	if !slf.running || (slf.filter != nil && !slf.filter(conn)) {
+		return
+	}
+	record := &PacketRecord{
+		Time:      time.Now().UnixNano(),
+		Conn:      conn.GetID(),
+		Direction: direction,
+		WST:       wst,
+		Packet:    append([]byte(nil), packet...),
+	}
+	select {
+	case slf.records <- record:
+	default:
+		slf.dropped.Add(1)
+	}
+}
+
+// run 开始将记录写入录制文件
+func (slf *recorder) run() {
+	defer close(slf.done)
+	for record := range slf.records {
+		if err := slf.write(record); err != nil {
+			log.Error("Server", log.String("Recorder", slf.dir), log.Err(err))
+		}
+		if len(slf.records) == 0 && slf.writer != nil {
+			_ = slf.writer.Flush()
+		}
+	}
+	if slf.writer != nil {
+		_ = slf.writer.Flush()
+		_ = slf.file.Close()
+	}
+}
+
+// write 将记录写入录制文件，当前文件超出大小限制时将创建新的录制文件
+func (slf *recorder) write(record *PacketRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if slf.file == nil || slf.written+int64(len(data)) > slf.size {
+		if err = slf.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := slf.writer.Write(data)
+	slf.written += int64(n)
+	return err
+}
+
+// rotate 创建新的录制文件，并移除超出保留数量的旧文件
+func (slf *recorder) rotate() error {
+	if slf.file != nil {
+		_ = slf.writer.Flush()
+		_ = slf.file.Close()
+		slf.file, slf.writer = nil, nil
+	}
+	if err := os.MkdirAll(slf.dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(slf.dir, fmt.Sprintf("%s%d%s", recorderFilePrefix, time.Now().UnixNano(), recorderFileSuffix)))
+	if err != nil {
+		return err
+	}
+	slf.file, slf.writer, slf.written = file, bufio.NewWriter(file), 0
+
+	files, err := RecordFiles(slf.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > slf.count {
+		_ = os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// RecordFiles 获取目录中通过 WithPacketRecorder 录制的文件，按录制时间排序
+func RecordFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, recorderFilePrefix+"*"+recorderFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runRecorder 开始运行数据包录制器
+func (slf *Server) runRecorder() {
+	if slf.recorder == nil {
+		return
+	}
+	slf.recorder.rw.Lock()
+	defer slf.recorder.rw.Unlock()
+	slf.recorder.records = make(chan *PacketRecord, DefaultRecorderBufferSize)
+	slf.recorder.done = make(chan struct{})
+	slf.recorder.running = true
+	go slf.recorder.run()
+}
+
+// stopRecorder 停止数据包录制器，并等待缓冲区中的记录写入完成
+func (slf *Server) stopRecorder() {
+	if slf.recorder == nil {
+		return
+	}
+	slf.recorder.rw.Lock()
+	if !slf.recorder.running {
+		slf.recorder.rw.Unlock()
+		return
+	}
+	slf.recorder.running = false
+	close(slf.recorder.records)
+	slf.recorder.rw.Unlock()
+	<-slf.recorder.done
+	if dropped := slf.recorder.dropped.Load(); dropped > 0 {
+		log.Warn("Server", log.String("Recorder", slf.recorder.dir), log.Int64("dropped", dropped))
+	}
+}
+
+// loopbackAddr 回环连接的地址
+type loopbackAddr string
+
+func (slf loopbackAddr) Network() string {
+	return "loopback"
+}
+
+func (slf loopbackAddr) String() string {
+	return string(slf)
+}
+
+// NewLoopbackConn 创建一个回环连接，通过 Conn.Write 写入的数据包将直接交由 writer 处理
+//   - id 将作为连接的ID及远程地址，适用于测试及数据包回放
+func NewLoopbackConn(server *Server, id string, writer func(packet []byte)) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			server:     server,
+			remoteAddr: loopbackAddr(id),
+			ip:         id,
+			data:       map[any]any{},
+			gw:         writer,
+		},
+	}
+	c.touch()
+	return c
+}
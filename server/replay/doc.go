@@ -0,0 +1,4 @@
+// Package replay 提供了对 server.WithPacketRecorder 录制的数据包进行回放的实现
+//   - 回放时将为每个录制的连接创建 server.NewLoopbackConn 回环连接，并按原始或加速后的时间间隔将接收到的数据包重新推送至服务器
+//   - 回放完成后将对比服务器实际写入的数据包与录制时写入的数据包，输出差异报告，可用于复现线上问题或进行回归测试
+package replay
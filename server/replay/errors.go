@@ -0,0 +1,7 @@
+package replay
+
+import "errors"
+
+var (
+	ErrNoRecords = errors.New("no records to replay")
+)
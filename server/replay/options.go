@@ -0,0 +1,42 @@
+package replay
+
+import "time"
+
+const (
+	DefaultSpeed  = 1.0             // 默认回放速度
+	DefaultSettle = 3 * time.Second // 默认等待服务器写入数据包的最长时间
+)
+
+// Option 回放器可选项
+type Option func(replayer *Replayer)
+
+// WithSpeed 通过特定的回放速度创建回放器
+//   - speed 为回放速度的倍数，例如 2 表示以两倍速回放，小于等于 0 时将忽略录制时的时间间隔，尽可能快地回放
+//   - 默认值为 DefaultSpeed，即按照录制时的时间间隔回放
+func WithSpeed(speed float64) Option {
+	return func(replayer *Replayer) {
+		replayer.speed = speed
+	}
+}
+
+// WithSettle 通过特定的等待时间创建回放器
+//   - 所有数据包推送完成后，将最多等待 settle 时间以便服务器处理完成并写入数据包，随后进行对比
+//   - 默认值为 DefaultSettle
+func WithSettle(settle time.Duration) Option {
+	return func(replayer *Replayer) {
+		replayer.settle = settle
+	}
+}
+
+// WithConn 通过仅回放特定连接的方式创建回放器
+//   - 默认回放录制中的所有连接
+func WithConn(ids ...string) Option {
+	return func(replayer *Replayer) {
+		if replayer.conns == nil {
+			replayer.conns = map[string]bool{}
+		}
+		for _, id := range ids {
+			replayer.conns[id] = true
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Load 读取录制文件中的记录，并按记录时间排序
+func Load(paths ...string) ([]*server.PacketRecord, error) {
+	var records []*server.PacketRecord
+	for _, path := range paths {
+		if err := func() error {
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+			for line := 1; scanner.Scan(); line++ {
+				if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+					continue
+				}
+				var record = new(server.PacketRecord)
+				if err = json.Unmarshal(scanner.Bytes(), record); err != nil {
+					return fmt.Errorf("%s:%d: %w", path, line, err)
+				}
+				records = append(records, record)
+			}
+			return scanner.Err()
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time < records[j].Time
+	})
+	return records, nil
+}
+
+// LoadDir 读取目录中所有录制文件的记录，并按记录时间排序
+func LoadDir(dir string) ([]*server.PacketRecord, error) {
+	files, err := server.RecordFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Load(files...)
+}
+
+// New 创建一个将 records 回放至 srv 的回放器
+func New(srv *server.Server, records []*server.PacketRecord, options ...Option) *Replayer {
+	replayer := &Replayer{
+		srv:     srv,
+		records: records,
+		speed:   DefaultSpeed,
+		settle:  DefaultSettle,
+	}
+	for _, option := range options {
+		option(replayer)
+	}
+	return replayer
+}
+
+// Replayer 数据包回放器
+type Replayer struct {
+	srv     *server.Server         // 回放的目标服务器
+	records []*server.PacketRecord // 回放的记录
+	speed   float64                // 回放速度
+	settle  time.Duration          // 等待服务器写入数据包的最长时间
+	conns   map[string]bool        // 仅回放的连接
+	actual  map[string][][]byte    // 回放时服务器写入的数据包
+	l       sync.Mutex             // 写入数据包锁
+}
+
+// Run 开始回放，将阻塞直到回放完成
+//   - 应在服务器启动完成后调用，且不应在服务器的消息循环中调用
+//   - 录制中的连接打开及关闭记录将通过 ConnectionOpenedEvent 及 ConnectionClosedEvent 进行回放，在录制中途开始的连接将在首个数据包时创建
+func (slf *Replayer) Run() (*Report, error) {
+	var conns = map[string]*server.Conn{}
+	var expected = map[string][][]byte{}
+	var inbound int
+	slf.actual = map[string][][]byte{}
+
+	var prev int64
+	for _, record := range slf.records {
+		if slf.conns != nil && !slf.conns[record.Conn] {
+			continue
+		}
+		if prev != 0 && slf.speed > 0 {
+			if wait := time.Duration(float64(record.Time-prev) / slf.speed); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		prev = record.Time
+
+		conn, exist := conns[record.Conn]
+		switch record.Direction {
+		case server.PacketRecordOpened:
+			if exist {
+				continue
+			}
+			conn = slf.conn(record.Conn)
+			conns[record.Conn] = conn
+			slf.srv.OnConnectionOpenedEvent(conn)
+		case server.PacketRecordInbound:
+			if !exist {
+				conn = slf.conn(record.Conn)
+				conns[record.Conn] = conn
+			}
+			inbound++
+			server.PushPacketMessage(slf.srv, conn, record.WST, record.Packet)
+		case server.PacketRecordOutbound:
+			expected[record.Conn] = append(expected[record.Conn], record.Packet)
+		case server.PacketRecordClosed:
+			if exist {
+				conn.Close()
+			}
+		}
+	}
+	if len(conns) == 0 && len(expected) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	var deadline = time.Now().Add(slf.settle)
+	for time.Now().Before(deadline) && !slf.settled(expected) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	slf.l.Lock()
+	defer slf.l.Unlock()
+	return newReport(len(conns), inbound, expected, slf.actual), nil
+}
+
+// conn 创建回放使用的回环连接
+func (slf *Replayer) conn(id string) *server.Conn {
+	return server.NewLoopbackConn(slf.srv, id, func(packet []byte) {
+		slf.l.Lock()
+		defer slf.l.Unlock()
+		slf.actual[id] = append(slf.actual[id], append([]byte(nil), packet...))
+	})
+}
+
+// settled 服务器写入的数据包数量是否已达到录制时的数量
+func (slf *Replayer) settled(expected map[string][][]byte) bool {
+	slf.l.Lock()
+	defer slf.l.Unlock()
+	for id, packets := range expected {
+		if len(slf.actual[id]) < len(packets) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff 回放时写入的数据包与录制时写入的数据包之间的差异
+type Diff struct {
+	Conn     string // 连接ID
+	Index    int    // 数据包在该连接写入的数据包中的索引
+	Expected []byte // 录制时写入的数据包，为 nil 时表示回放时多写入了数据包
+	Actual   []byte // 回放时写入的数据包，为 nil 时表示回放时缺少该数据包
+}
+
+// Report 回放报告
+type Report struct {
+	Conns    int     // 回放的连接数量
+	Inbound  int     // 回放的接收数据包数量
+	Expected int     // 录制时写入的数据包数量
+	Actual   int     // 回放时写入的数据包数量
+	Diffs    []*Diff // 差异，按连接ID及索引排序
+}
+
+// newReport 对比录制时及回放时写入的数据包并生成报告
+func newReport(conns, inbound int, expected, actual map[string][][]byte) *Report {
+	report := &Report{Conns: conns, Inbound: inbound}
+	var ids = map[string]struct{}{}
+	for id, packets := range expected {
+		ids[id] = struct{}{}
+		report.Expected += len(packets)
+	}
+	for id, packets := range actual {
+		ids[id] = struct{}{}
+		report.Actual += len(packets)
+	}
+	for id := range ids {
+		e, a := expected[id], actual[id]
+		for i := 0; i < len(e) || i < len(a); i++ {
+			var diff = &Diff{Conn: id, Index: i}
+			if i < len(e) {
+				diff.Expected = e[i]
+			}
+			if i < len(a) {
+				diff.Actual = a[i]
+			}
+			if i < len(e) && i < len(a) && bytes.Equal(diff.Expected, diff.Actual) {
+				continue
+			}
+			report.Diffs = append(report.Diffs, diff)
+		}
+	}
+	sort.Slice(report.Diffs, func(i, j int) bool {
+		if report.Diffs[i].Conn != report.Diffs[j].Conn {
+			return report.Diffs[i].Conn < report.Diffs[j].Conn
+		}
+		return report.Diffs[i].Index < report.Diffs[j].Index
+	})
+	return report
+}
+
+// OK 回放时写入的数据包是否与录制时完全一致
+func (slf *Report) OK() bool {
+	return len(slf.Diffs) == 0
+}
+
+// String 获取可读的回放报告
+func (slf *Report) String() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "conns: %d, inbound: %d, expected: %d, actual: %d, diffs: %d\n",
+		slf.Conns, slf.Inbound, slf.Expected, slf.Actual, len(slf.Diffs))
+	for _, diff := range slf.Diffs {
+		_, _ = fmt.Fprintf(&builder, "  %s #%d\n    expected: %q\n    actual:   %q\n", diff.Conn, diff.Index, diff.Expected, diff.Actual)
+	}
+	return builder.String()
+}
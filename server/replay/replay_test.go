@@ -0,0 +1,74 @@
+package replay_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/replay"
+	"testing"
+	"time"
+)
+
+func run(t *testing.T, prefix string, options ...server.Option) *server.Server {
+	var started = make(chan struct{})
+	srv := server.New(server.NetworkNone, options...)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(append([]byte(prefix), packet...))
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	<-started
+	return srv
+}
+
+func TestReplayer_Run(t *testing.T) {
+	var dir = t.TempDir()
+	var written = make(chan []byte, 3)
+	srv := run(t, "echo:", server.WithPacketRecorder(dir, 0, 0))
+	conn := server.NewLoopbackConn(srv, "player", func(packet []byte) {
+		written <- packet
+	})
+	srv.OnConnectionOpenedEvent(conn)
+	for _, packet := range []string{"a", "b", "c"} {
+		server.PushPacketMessage(srv, conn, 0, []byte(packet))
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-written:
+		case <-time.After(5 * time.Second):
+			t.Fatal("write timeout")
+		}
+	}
+	conn.Close()
+	srv.Shutdown()
+
+	records, err := replay.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv = run(t, "echo:")
+	report, err := replay.New(srv, records, replay.WithSpeed(0)).Run()
+	srv.Shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Conns != 1 || report.Inbound != 3 || report.Expected != 3 {
+		t.Fatal(report)
+	}
+
+	srv = run(t, "reply:")
+	report, err = replay.New(srv, records, replay.WithSpeed(10)).Run()
+	srv.Shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || len(report.Diffs) != 3 || string(report.Diffs[0].Actual) != "reply:a" {
+		t.Fatal(report)
+	}
+}
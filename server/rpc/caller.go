@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"github.com/kercylan98/minotaur/server/client"
+	"sync"
+	"sync/atomic"
+)
+
+// NewCaller 基于 client.Client 创建 RPC 调用端
+//   - 调用端将注册客户端的数据包接收事件以获取响应，客户端其他的数据包接收事件依旧会收到 RPC 数据包，可通过 IsPacket 函数进行过滤
+func NewCaller(cli *client.Client, options ...Option) *Caller {
+	caller := &Caller{
+		option:  newOption(options...),
+		cli:     cli,
+		pending: make(map[uint64]chan *reply),
+	}
+	cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		kind, id, route, payload, err := unmarshalPacket(packet)
+		if err != nil || kind == packetKindRequest {
+			return
+		}
+		caller.reply(id, route, kind, payload)
+	})
+	cli.RegConnectionClosedEvent(func(conn *client.Client, err any) {
+		caller.hello.Store(false)
+		caller.release()
+	})
+	return caller
+}
+
+// Caller RPC 调用端
+type Caller struct {
+	*option
+	cli     *client.Client         // 客户端
+	seq     atomic.Uint64          // 请求序号
+	hello   atomic.Bool            // 是否已向当前连接发送声明数据包
+	pending map[uint64]chan *reply // 等待响应的调用
+	lock    sync.Mutex             // 调用锁
+}
+
+// reply 调用响应
+type reply struct {
+	data []byte
+	err  error
+}
+
+// Call 发起调用并等待响应，请求及响应将通过调用端的 Codec 进行序列化
+//   - resp 应为指针类型，当不关心响应内容时可传入 nil
+//   - 当 ctx 未设置截止时间时，将使用 WithCallTimeout 设置的超时时间
+//   - 远端处理函数返回的错误将以 *Error 类型返回
+func (slf *Caller) Call(ctx context.Context, route string, req any, resp any) error {
+	data, err := slf.codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	data, err = slf.Invoke(ctx, route, data)
+	if err != nil || resp == nil {
+		return err
+	}
+	return slf.codec.Unmarshal(data, resp)
+}
+
+// Invoke 发起未经序列化的调用并等待响应
+//   - 每个连接的首次调用前将发送声明数据包，服务端仅拦截已声明连接中的 RPC 请求
+func (slf *Caller) Invoke(ctx context.Context, route string, data []byte) ([]byte, error) {
+	if !slf.cli.IsConnected() {
+		return nil, ErrCallerNotActive
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, slf.timeout)
+		defer cancel()
+	}
+
+	id := slf.seq.Add(1)
+	packet, err := marshalPacket(packetKindRequest, id, route, data)
+	if err != nil {
+		return nil, err
+	}
+	wait := make(chan *reply, 1)
+	slf.lock.Lock()
+	slf.pending[id] = wait
+	slf.lock.Unlock()
+	defer func() {
+		slf.lock.Lock()
+		delete(slf.pending, id)
+		slf.lock.Unlock()
+	}()
+
+	if !slf.hello.Swap(true) {
+		hello, _ := marshalPacket(packetKindHello, 0, "", nil)
+		slf.cli.Write(hello)
+	}
+	slf.cli.Write(packet, func(err error) {
+		if err != nil {
+			slf.reply(id, route, 0, nil, err)
+		}
+	})
+
+	select {
+	case r := <-wait:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reply 将响应交付给等待中的调用
+func (slf *Caller) reply(id uint64, route string, kind byte, payload []byte, err ...error) {
+	slf.lock.Lock()
+	wait, exist := slf.pending[id]
+	delete(slf.pending, id)
+	slf.lock.Unlock()
+	if !exist {
+		return
+	}
+	r := &reply{data: bytes.Clone(payload)}
+	switch {
+	case len(err) > 0:
+		r.err = err[0]
+	case kind == packetKindError:
+		r.err = &Error{Route: route, Message: string(payload)}
+	}
+	wait <- r
+}
+
+// release 结束所有等待中的调用
+func (slf *Caller) release() {
+	slf.lock.Lock()
+	pending := slf.pending
+	slf.pending = make(map[uint64]chan *reply)
+	slf.lock.Unlock()
+	for _, wait := range pending {
+		wait <- &reply{err: ErrCallerClosed}
+	}
+}
@@ -0,0 +1,22 @@
+package rpc
+
+import "encoding/json"
+
+// Codec 请求及响应的序列化方式
+type Codec interface {
+	// Marshal 序列化
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 反序列化
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec 基于 JSON 的序列化方式
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,5 @@
+// Package rpc 提供了基于服务器数据包管道的请求/响应调用实现
+//   - 服务端通过 NewServer 创建，并通过 Register 注册强类型的处理函数
+//   - 客户端通过 NewCaller 基于 client.Client 创建，并通过 Caller.Call 发起调用等待响应
+//   - RPC 数据包拥有独立的标识，不会影响服务器及客户端中其他数据包的正常处理
+package rpc
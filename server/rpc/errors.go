@@ -0,0 +1,21 @@
+package rpc
+
+import "errors"
+
+var (
+	ErrRouteNotFound   = errors.New("the rpc route does not exist")
+	ErrInvalidPacket   = errors.New("invalid rpc packet")
+	ErrCallerClosed    = errors.New("the rpc caller connection has been closed")
+	ErrCallerNotActive = errors.New("the rpc caller connection is not connected")
+	ErrRouteTooLong    = errors.New("the rpc route is too long")
+)
+
+// Error 远端处理调用时返回的错误
+type Error struct {
+	Route   string // 调用的路由
+	Message string // 错误信息
+}
+
+func (slf *Error) Error() string {
+	return "rpc: " + slf.Route + ": " + slf.Message
+}
@@ -0,0 +1,47 @@
+package rpc
+
+import "time"
+
+const (
+	DefaultCallTimeout = 10 * time.Second
+)
+
+// Option RPC 服务端及调用端的可选项
+type Option func(opt *option)
+
+type option struct {
+	codec   Codec         // 序列化方式
+	timeout time.Duration // 默认调用超时时间
+}
+
+func newOption(options ...Option) *option {
+	opt := &option{
+		codec:   JSONCodec{},
+		timeout: DefaultCallTimeout,
+	}
+	for _, o := range options {
+		o(opt)
+	}
+	return opt
+}
+
+// WithCodec 通过特定的序列化方式创建
+//   - 默认为 JSONCodec，服务端与调用端需要保持一致
+func WithCodec(codec Codec) Option {
+	return func(opt *option) {
+		if codec != nil {
+			opt.codec = codec
+		}
+	}
+}
+
+// WithCallTimeout 设置调用端的默认调用超时时间
+//   - 仅在调用时传入的 context.Context 未设置截止时间时生效
+//   - 默认为 DefaultCallTimeout
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(opt *option) {
+		if timeout > 0 {
+			opt.timeout = timeout
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+var packetIdentifier = []byte{0x52, 0x50, 0x43} // RPC
+
+const (
+	packetKindRequest  byte = iota + 1 // 请求
+	packetKindResponse                 // 响应
+	packetKindError                    // 错误响应
+	packetKindHello                    // 调用端声明，声明后连接中的 RPC 请求才会被服务端拦截
+)
+
+const packetHeaderSize = 3 + 1 + 8 + 2
+
+// IsPacket 检查数据包是否为 RPC 数据包
+//   - 适用于在 client.Client 的数据包接收事件中忽略 RPC 数据包
+func IsPacket(packet []byte) bool {
+	return len(packet) >= packetHeaderSize && packet[0] == packetIdentifier[0] && packet[1] == packetIdentifier[1] && packet[2] == packetIdentifier[2]
+}
+
+// marshalPacket 将调用信息转换为 RPC 数据包
+//   - | identifier(3) | kind(1) | id(8) | routeLength(2) | route | payload |
+//   - 路由长度超出 math.MaxUint16 时将返回 ErrRouteTooLong
+func marshalPacket(kind byte, id uint64, route string, payload []byte) ([]byte, error) {
+	if len(route) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: %d > %d", ErrRouteTooLong, len(route), math.MaxUint16)
+	}
+	data := make([]byte, packetHeaderSize+len(route)+len(payload))
+	copy(data, packetIdentifier)
+	data[3] = kind
+	binary.BigEndian.PutUint64(data[4:12], id)
+	binary.BigEndian.PutUint16(data[12:14], uint16(len(route)))
+	copy(data[packetHeaderSize:], route)
+	copy(data[packetHeaderSize+len(route):], payload)
+	return data, nil
+}
+
+// unmarshalPacket 将 RPC 数据包转换为调用信息
+//   - | identifier(3) | kind(1) | id(8) | routeLength(2) | route | payload |
+func unmarshalPacket(data []byte) (kind byte, id uint64, route string, payload []byte, err error) {
+	if !IsPacket(data) {
+		err = ErrInvalidPacket
+		return
+	}
+	kind = data[3]
+	id = binary.BigEndian.Uint64(data[4:12])
+	routeLength := int(binary.BigEndian.Uint16(data[12:14]))
+	if len(data) < packetHeaderSize+routeLength {
+		err = ErrInvalidPacket
+		return
+	}
+	route = string(data[packetHeaderSize : packetHeaderSize+routeLength])
+	payload = data[packetHeaderSize+routeLength:]
+	return
+}
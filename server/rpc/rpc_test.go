@@ -0,0 +1,119 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"github.com/kercylan98/minotaur/server/rpc"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Echo struct {
+	Content string `json:"content"`
+}
+
+func TestCaller_Call(t *testing.T) {
+	var done = make(chan struct{})
+	srv := server.New(server.NetworkWebsocket)
+	s := rpc.NewServer(srv)
+	rpc.Register[Echo, Echo](s, "echo", func(conn *server.Conn, req *Echo) (*Echo, error) {
+		if len(req.Content) == 0 {
+			return nil, errors.New("empty content")
+		}
+		return req, nil
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		go func() {
+			defer close(done)
+			cli := client.NewWebsocket("ws://127.0.0.1:9996")
+			caller := rpc.NewCaller(cli)
+			if err := cli.Run(); err != nil {
+				t.Error(err)
+				return
+			}
+			defer cli.Close()
+
+			var resp Echo
+			if err := caller.Call(context.Background(), "echo", &Echo{Content: "Hello"}, &resp); err != nil || resp.Content != "Hello" {
+				t.Error(resp, err)
+			}
+			var remote *rpc.Error
+			if err := caller.Call(context.Background(), "echo", &Echo{}, &resp); !errors.As(err, &remote) {
+				t.Error(err)
+			}
+			if err := caller.Call(context.Background(), "none", &Echo{}, nil); err == nil {
+				t.Error("expect route not found")
+			}
+			if err := caller.Call(context.Background(), strings.Repeat("r", math.MaxUint16+1), &Echo{}, nil); !errors.Is(err, rpc.ErrRouteTooLong) {
+				t.Error(err)
+			}
+		}()
+	})
+	go func() {
+		<-done
+		srv.Shutdown()
+	}()
+	if err := srv.Run(":9996"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewServer_Hello(t *testing.T) {
+	var started = make(chan struct{})
+	var received = make(chan []byte, 1)
+	var written = make(chan []byte, 1)
+	srv := server.New(server.NetworkNone)
+	s := rpc.NewServer(srv)
+	s.Route("echo", func(conn *server.Conn, data []byte) ([]byte, error) {
+		return data, nil
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- packet
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	// | identifier(3) | kind(1) | id(8) | routeLength(2) | route | payload |
+	request := append([]byte("RPC\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x04echo"), "ping"...)
+	hello := []byte("RPC\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	conn := server.NewLoopbackConn(srv, "rpc", func(packet []byte) {
+		written <- packet
+	})
+
+	// 未声明的连接中以保留前缀开头的数据包不会被拦截
+	server.PushPacketMessage(srv, conn, 0, request)
+	select {
+	case packet := <-received:
+		if string(packet) != string(request) {
+			t.Fatal(string(packet))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet intercepted before hello")
+	}
+
+	server.PushPacketMessage(srv, conn, 0, hello)
+	server.PushPacketMessage(srv, conn, 0, request)
+	select {
+	case packet := <-written:
+		if !rpc.IsPacket(packet) || !strings.HasSuffix(string(packet), "echoping") {
+			t.Fatal(string(packet))
+		}
+	case packet := <-received:
+		t.Fatal("packet not intercepted after hello", string(packet))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response")
+	}
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/router"
+	"github.com/kercylan98/minotaur/utils/log"
+	"math"
+	"runtime/debug"
+)
+
+// Handler 未经反序列化的 RPC 处理函数
+type Handler func(conn *server.Conn, data []byte) ([]byte, error)
+
+// NewServer 基于 server.Server 创建 RPC 服务端
+//   - RPC 服务端将通过 RegConnectionPacketPreprocessEvent 拦截 RPC 数据包，其他数据包依旧会交由 ConnectionReceivePacketEvent 处理
+//   - 以 "RPC"（0x52 0x50 0x43）开头的数据包为保留前缀，仅在连接通过 Caller 发送声明数据包后才会被拦截，未声明的连接中的数据包将原样交由 ConnectionReceivePacketEvent 处理
+func NewServer(srv *server.Server, options ...Option) *Server {
+	s := &Server{
+		option: newOption(options...),
+		srv:    srv,
+		router: router.NewLevel1Router[string, Handler](),
+	}
+	srv.RegConnectionPacketPreprocessEvent(func(srv *server.Server, conn *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
+		kind, id, route, payload, err := unmarshalPacket(packet)
+		if err != nil {
+			return
+		}
+		switch kind {
+		case packetKindHello:
+			abort()
+			conn.SetData(helloKey{}, true)
+		case packetKindRequest:
+			if conn.GetData(helloKey{}) == nil {
+				return
+			}
+			abort()
+			s.handle(conn, id, route, payload)
+		}
+	}, math.MinInt)
+	return s
+}
+
+// helloKey 连接已发送声明数据包的标记
+type helloKey struct{}
+
+// Server RPC 服务端
+type Server struct {
+	*option
+	srv    *server.Server                        // 服务器
+	router *router.Level1Router[string, Handler] // 路由器
+}
+
+// Route 注册未经反序列化的处理函数
+//   - 重复注册同一路由将会发生 panic
+func (slf *Server) Route(route string, handler Handler) {
+	slf.router.Route(route, handler)
+}
+
+// Register 注册强类型的处理函数，请求及响应将通过服务端的 Codec 进行序列化
+//   - 处理函数返回的 error 将作为错误响应返回给调用端
+func Register[Req, Resp any](s *Server, route string, handler func(conn *server.Conn, req *Req) (*Resp, error)) {
+	s.Route(route, func(conn *server.Conn, data []byte) ([]byte, error) {
+		var req = new(Req)
+		if err := s.codec.Unmarshal(data, req); err != nil {
+			return nil, err
+		}
+		resp, err := handler(conn, req)
+		if err != nil {
+			return nil, err
+		}
+		return s.codec.Marshal(resp)
+	})
+}
+
+// handle 处理调用请求并响应
+func (slf *Server) handle(conn *server.Conn, id uint64, route string, payload []byte) {
+	var (
+		resp []byte
+		err  error
+	)
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("RPC", log.String("route", route), log.Any("error", e), log.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("%v", e)
+		}
+		// 路由由请求数据包解析而来，长度不会超出限制
+		var packet []byte
+		if err != nil {
+			packet, _ = marshalPacket(packetKindError, id, route, []byte(err.Error()))
+		} else {
+			packet, _ = marshalPacket(packetKindResponse, id, route, resp)
+		}
+		conn.Write(packet)
+	}()
+
+	handler := slf.router.Match(route)
+	if handler == nil {
+		err = ErrRouteNotFound
+		return
+	}
+	resp, err = handler(conn, payload)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"crypto/ecdh"
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultSecureHandshakeTimeout = 10 * time.Second // 默认加密传输密钥交换超时时间
+)
+
+// secureConfig 加密传输配置
+type secureConfig struct {
+	cipher         crypto.SecureCipher // 加密算法
+	rotatePackets  uint64              // 轮换密钥的数据包数量
+	rotateInterval time.Duration       // 轮换密钥的时间间隔
+	timeout        time.Duration       // 密钥交换超时时间
+	psk            []byte              // 参与密钥派生的预共享密钥，为空时密钥交换未经认证
+}
+
+// connSecure 连接的加密传输状态
+type connSecure struct {
+	state   atomic.Int32                         // 密钥交换状态
+	private *ecdh.PrivateKey                     // 本地私钥
+	session atomic.Pointer[crypto.SecureSession] // 密钥交换完成后的安全会话
+	timer   *time.Timer                          // 超时定时器
+	next    func()                               // 密钥交换完成后继续打开连接的函数
+}
+
+// IsSecure 连接是否已建立加密传输
+func (slf *Conn) IsSecure() bool {
+	return slf.secure != nil && slf.secure.session.Load() != nil
+}
+
+// abort 在连接关闭时中止密钥交换，返回连接是否未完成密钥交换
+func (slf *connSecure) abort() bool {
+	slf.state.CompareAndSwap(handshakePending, handshakeFailed)
+	if slf.timer != nil {
+		slf.timer.Stop()
+	}
+	return slf.state.Load() == handshakeFailed
+}
+
+// openSecure 开始与连接进行密钥交换，交换完成后将执行 next
+//   - 服务器将以明文发送握手数据包，客户端需以明文回复其公钥，此后所有数据包均将被加密
+func (slf *Server) openSecure(conn *Conn, next func()) {
+	private, err := crypto.NewSecureKeyPair()
+	if err != nil {
+		conn.Close(err)
+		return
+	}
+	cs := &connSecure{private: private, next: next}
+	conn.secure = cs
+	if slf.secureConfig.timeout > 0 {
+		cs.timer = time.AfterFunc(slf.secureConfig.timeout, func() {
+			if cs.state.CompareAndSwap(handshakePending, handshakeFailed) {
+				conn.Close(ErrSecureHandshakeTimeout)
+			}
+		})
+	}
+	conn.write(crypto.EncodeSecureHandshake(slf.secureConfig.cipher, private.PublicKey()))
+}
+
+// unseal 解密连接接收到的数据包，返回 false 时表示数据包已被消费或连接已被关闭
+func (slf *Conn) unseal(packet []byte) ([]byte, bool) {
+	cs := slf.secure
+	if session := cs.session.Load(); session != nil {
+		data, err := session.Open(packet)
+		if err != nil {
+			// 数据报可能被伪造或重复投递，丢弃即可，流式传输出现错误则说明连接已不可信
+			if !slf.isUDPSession() {
+				slf.Close(err)
+			}
+			return nil, false
+		}
+		return data, true
+	}
+
+	config := slf.server.secureConfig
+	cipher, public, err := crypto.DecodeSecureHandshake(packet)
+	if err == nil && cipher != config.cipher {
+		err = crypto.ErrSecureUnsupportedCipher
+	}
+	var session *crypto.SecureSession
+	if err == nil {
+		session, err = crypto.NewSecureSession(config.cipher, cs.private, public, true, config.rotatePackets, config.rotateInterval, config.psk)
+	}
+	if err == nil && slf.isUDPSession() {
+		session.EnableReplayWindow()
+	}
+	if err != nil {
+		if cs.state.CompareAndSwap(handshakePending, handshakeFailed) {
+			slf.Close(err)
+		}
+		return nil, false
+	}
+	cs.session.Store(session)
+	if cs.state.CompareAndSwap(handshakePending, handshakePassed) {
+		if cs.timer != nil {
+			cs.timer.Stop()
+		}
+		cs.private = nil
+		if cs.next != nil {
+			cs.next()
+		}
+	}
+	return nil, false
+}
+
+// seal 加密即将写入连接的数据包，密钥交换完成前的数据包将以明文写入
+func (slf *Conn) seal(packet []byte) ([]byte, error) {
+	if slf.secure == nil {
+		return packet, nil
+	}
+	session := slf.secure.session.Load()
+	if session == nil {
+		return packet, nil
+	}
+	return session.Seal(packet)
+}
@@ -0,0 +1,183 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithSecureTransport(t *testing.T) {
+	var failed = make(chan error, 4)
+	srv := server.New(server.NetworkWebsocket, server.WithSecureTransport(crypto.SecureCipherAESGCM, 2, 0, 200*time.Millisecond))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if !conn.IsSecure() {
+			t.Error("packet received before secure session established")
+		}
+		conn.Write(packet)
+	})
+	srv.RegConnectionHandshakeFailedEvent(func(srv *server.Server, conn *server.Conn, err error) {
+		failed <- err
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9988"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	var received = make(chan string, 8)
+	cli := client.NewWebsocket("ws://127.0.0.1:9988").EnableSecure(3, 0, 0)
+	cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		received <- string(packet)
+	})
+	if err := cli.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	var packets = []string{"a", "b", "c", "d", "e", "f", "g"}
+	for _, packet := range packets {
+		cli.Write([]byte(packet))
+	}
+	for _, expect := range packets {
+		select {
+		case packet := <-received:
+			if packet != expect {
+				t.Fatal(packet, expect)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("echo timeout")
+		}
+	}
+
+	// 密钥交换后重放同一个数据包将导致连接被关闭
+	ws, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:9988", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	_, hello, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, public, err := crypto.DecodeSecureHandshake(hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, _ := crypto.NewSecureKeyPair()
+	session, err := crypto.NewSecureSession(cipher, private, public, false, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = ws.WriteMessage(websocket.BinaryMessage, crypto.EncodeSecureHandshake(cipher, private.PublicKey()))
+	frame, _ := session.Seal([]byte("replay"))
+	_ = ws.WriteMessage(websocket.BinaryMessage, frame)
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, echo, err := ws.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if packet, err := session.Open(echo); err != nil || string(packet) != "replay" {
+		t.Fatal(string(packet), err)
+	}
+	_ = ws.WriteMessage(websocket.BinaryMessage, frame)
+	if _, _, err = ws.ReadMessage(); err == nil {
+		t.Fatal("replayed packet accepted")
+	}
+
+	// 未完成密钥交换的连接将在超时后关闭
+	idle, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:9988", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+	select {
+	case err := <-failed:
+		if err != server.ErrSecureHandshakeTimeout {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("secure handshake timeout not triggered")
+	}
+}
+
+func TestWithSecureTransport_PSK(t *testing.T) {
+	var psk = []byte("minotaur")
+	var ready = make(chan struct{})
+	srv := server.New(server.NetworkWebsocket, server.WithSecureTransport(crypto.SecureCipherChaCha20Poly1305, 0, 0, 0, psk))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9977"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	var received = make(chan string, 1)
+	cli := client.NewWebsocket("ws://127.0.0.1:9977").EnableSecure(0, 0, 0, psk)
+	cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
+		received <- string(packet)
+	})
+	if err := cli.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	cli.Write([]byte("hello"))
+	select {
+	case packet := <-received:
+		if packet != "hello" {
+			t.Fatal(packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("echo timeout")
+	}
+
+	// 未持有预共享密钥的一方即便完成了密钥交换，其数据包也无法通过认证
+	ws, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:9977", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	_, hello, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, public, err := crypto.DecodeSecureHandshake(hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, _ := crypto.NewSecureKeyPair()
+	session, err := crypto.NewSecureSession(cipher, private, public, false, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = ws.WriteMessage(websocket.BinaryMessage, crypto.EncodeSecureHandshake(cipher, private.PublicKey()))
+	frame, _ := session.Seal([]byte("forged"))
+	_ = ws.WriteMessage(websocket.BinaryMessage, frame)
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var timeout net.Error
+	if _, _, err = ws.ReadMessage(); err == nil || errors.As(err, &timeout) && timeout.Timeout() {
+		t.Fatal("packet without psk accepted", err)
+	}
+}
@@ -5,16 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/utils/concurrent"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/str"
-	"github.com/kercylan98/minotaur/utils/super"
 	"github.com/kercylan98/minotaur/utils/timer"
-	"github.com/kercylan98/minotaur/utils/times"
 	"github.com/panjf2000/ants/v2"
 	"github.com/panjf2000/gnet"
-	"github.com/panjf2000/gnet/pkg/logging"
+	"github.com/quic-go/quic-go"
 	"github.com/xtaci/kcp-go/v5"
 	"google.golang.org/grpc"
 	"net"
@@ -23,6 +20,7 @@ import (
 	"os/signal"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -51,12 +49,20 @@ func New(network Network, options ...Option) *Server {
 		server.grpcServer = grpc.NewServer()
 	case NetworkWebsocket:
 		server.websocketReadDeadline = DefaultWebsocketReadDeadline
+		server.websocketMux = http.NewServeMux()
+		server.httpServer = &http.Server{
+			Handler: server.websocketMux,
+		}
 	}
 
 	for _, option := range options {
 		option(server)
 	}
 
+	if network == NetworkQUIC && server.packetCodec == nil {
+		server.packetCodec = NewLengthFieldCodec()
+	}
+
 	if !server.disableAnts {
 		if server.antsPoolSize <= 0 {
 			server.antsPoolSize = DefaultAsyncPoolSize
@@ -82,7 +88,8 @@ type Server struct {
 	systemSignal             chan os.Signal                                    // 系统信号
 	online                   *concurrent.BalanceMap[string, *Conn]             // 在线连接
 	ginServer                *gin.Engine                                       // HTTP模式下的路由器
-	httpServer               *http.Server                                      // HTTP模式下的服务器
+	httpServer               *http.Server                                      // HTTP、Websocket模式下的服务器
+	websocketMux             *http.ServeMux                                    // Websocket模式下的路由器
 	grpcServer               *grpc.Server                                      // GRPC模式下的服务器
 	gServer                  *gNet                                             // TCP或UDP模式下的服务器
 	isRunning                bool                                              // 是否正在运行
@@ -94,11 +101,21 @@ type Server struct {
 	multiple                 *MultipleServer                                   // 多服务器模式下的服务器
 	multipleRuntimeErrorChan chan error                                        // 多服务器模式下的运行时错误
 	runMode                  RunMode                                           // 运行模式
-	shuntChannels            *concurrent.BalanceMap[int64, chan *Message]      // 分流管道
+	shuntChannels            *concurrent.BalanceMap[int64, *shunt]             // 分流管道
 	channelGenerator         func(guid int64) chan *Message                    // 消息管道生成器
 	shuntMatcher             func(conn *Conn) (guid int64, allowToCreate bool) // 分流管道匹配器
 	messageCounter           atomic.Int64                                      // 消息计数器
 	ctx                      context.Context                                   // 上下文
+	ipConnections            map[string]int                                    // 各IP的连接数
+	ipConnectionsL           sync.Mutex                                        // 各IP的连接数锁
+	maintaining              atomic.Bool                                       // 是否处于维护模式
+	restarting               atomic.Bool                                       // 是否已将侦听器交接给热重启的新进程
+	listeners                map[string]net.Listener                           // 可交接的侦听器
+	listenersL               sync.Mutex                                        // 可交接的侦听器锁
+	packetMiddlewares        []PacketMiddleware                                // 数据包中间件
+	tcpListener              net.Listener                                      // 启用热重启时的 TCP 侦听器
+	kcpListener              *kcp.Listener                                     // KCP 侦听器
+	quicListener             *quic.Listener                                    // QUIC 侦听器
 }
 
 // Run 使用特定地址运行服务器
@@ -112,6 +129,7 @@ type Server struct {
 //   - server.NetworkHttp (addr:":8888")
 //   - server.NetworkWebsocket (addr:":8888/ws")
 //   - server.NetworkKcp (addr:":8888")
+//   - server.NetworkQUIC (addr:":8888")
 //   - server.NetworkNone (addr:"")
 func (slf *Server) Run(addr string) error {
 	if slf.network == NetworkNone {
@@ -132,6 +150,7 @@ func (slf *Server) Run(addr string) error {
 			func(data *Message) {
 				data.t = 0
 				data.attrs = nil
+				data.epoch = nil
 			},
 		)
 		slf.messageChannel = make(chan *Message, slf.messageChannelSize)
@@ -143,9 +162,10 @@ func (slf *Server) Run(addr string) error {
 		}
 		go func(messageChannel <-chan *Message) {
 			messageInitFinish <- struct{}{}
+			goroutine := slf.deadlockDetect.goroutine()
 			for message := range messageChannel {
 				msg := message
-				slf.dispatchMessage(msg)
+				slf.dispatchMessage(goroutine, msg)
 			}
 		}(slf.messageChannel)
 	}
@@ -157,7 +177,7 @@ func (slf *Server) Run(addr string) error {
 			slf.OnStartBeforeEvent()
 		})
 	case NetworkGRPC:
-		listener, err := net.Listen(string(NetworkTcp), slf.addr)
+		listener, err := slf.listen(string(NetworkTcp), slf.addr)
 		if err != nil {
 			return err
 		}
@@ -165,42 +185,68 @@ func (slf *Server) Run(addr string) error {
 		go func() {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
-			if err := slf.grpcServer.Serve(listener); err != nil {
+			if err := slf.grpcServer.Serve(listener); err != nil && !slf.restarting.Load() {
 				slf.isRunning = false
 				PushErrorMessage(slf, err, MessageErrorActionShutdown)
 			}
 		}()
 	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix:
+		if slf.isListenerTCP() {
+			listener, err := slf.listen(string(slf.network), slf.addr)
+			if err != nil {
+				return err
+			}
+			slf.tcpListener = listener
+			go connectionInitHandle(func() {
+				slf.isRunning = true
+				slf.OnStartBeforeEvent()
+				slf.serveTCP(listener)
+			})
+			break
+		}
 		go connectionInitHandle(func() {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
-			if err := gnet.Serve(slf.gServer, protoAddr,
-				gnet.WithLogger(log.GetLogger()),
-				gnet.WithLogLevel(super.If(slf.runMode == RunModeProd, logging.ErrorLevel, logging.DebugLevel)),
-				gnet.WithTicker(true),
-				gnet.WithMulticore(true),
-			); err != nil {
+			if err := gnet.Serve(slf.gServer, protoAddr, gnet.WithOptions(slf.gServer.options())); err != nil {
 				slf.isRunning = false
 				PushErrorMessage(slf, err, MessageErrorActionShutdown)
 			}
 		})
 	case NetworkKcp:
-		listener, err := kcp.ListenWithOptions(slf.addr, nil, 0, 0)
+		listener, err := slf.kcpOptions.listen(slf.addr)
 		if err != nil {
 			return err
 		}
+		slf.kcpListener = listener
 		go connectionInitHandle(func() {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
 			for {
 				session, err := listener.AcceptKCP()
 				if err != nil {
+					if slf.isShutdown.Load() {
+						return
+					}
 					continue
 				}
 
+				if packet, refused := slf.refuse(); refused {
+					if len(packet) > 0 {
+						_, _ = session.Write(packet)
+					}
+					_ = session.Close()
+					continue
+				}
+				if !slf.acquireIP(ipOf(session.RemoteAddr())) {
+					_ = session.Close()
+					continue
+				}
+				slf.kcpOptions.Apply(session)
 				conn := newKcpConn(slf, session)
-				slf.OnConnectionOpenedEvent(conn)
-				slf.OnConnectionOpenedAfterEvent(conn)
+				slf.openConn(conn, func() {
+					slf.OnConnectionOpenedEvent(conn)
+					slf.OnConnectionOpenedAfterEvent(conn)
+				})
 
 				go func(conn *Conn) {
 					defer func() {
@@ -213,20 +259,33 @@ func (slf *Server) Run(addr string) error {
 						}
 					}()
 
-					buf := make([]byte, 4096)
+					buf := make([]byte, slf.kcpOptions.GetReadBufferSize())
 					for !conn.IsClosed() {
 						n, err := conn.kcp.Read(buf)
 						if err != nil {
-							if conn.IsClosed() {
+							if conn.IsClosed() || slf.isShutdown.Load() {
 								break
 							}
 							panic(err)
 						}
-						PushPacketMessage(slf, conn, 0, buf[:n])
+						if err = conn.receive(0, buf[:n]); err != nil {
+							panic(err)
+						}
 					}
 				}(conn)
 			}
 		})
+	case NetworkQUIC:
+		listener, err := slf.listenQUIC()
+		if err != nil {
+			return err
+		}
+		slf.quicListener = listener
+		go connectionInitHandle(func() {
+			slf.isRunning = true
+			slf.OnStartBeforeEvent()
+			slf.serveQUIC(listener)
+		})
 	case NetworkHttp:
 		switch slf.runMode {
 		case RunModeDev:
@@ -236,18 +295,22 @@ func (slf *Server) Run(addr string) error {
 		case RunModeProd:
 			gin.SetMode(gin.ReleaseMode)
 		}
+		listener, err := slf.listen(string(NetworkTcp), slf.addr)
+		if err != nil {
+			return err
+		}
 		go func() {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
 			slf.httpServer.Addr = slf.addr
 			go connectionInitHandle(nil)
 			if len(slf.certFile)+len(slf.keyFile) > 0 {
-				if err := slf.httpServer.ListenAndServeTLS(slf.certFile, slf.keyFile); err != nil {
+				if err := slf.httpServer.ServeTLS(listener, slf.certFile, slf.keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) && !slf.restarting.Load() {
 					slf.isRunning = false
 					PushErrorMessage(slf, err, MessageErrorActionShutdown)
 				}
 			} else {
-				if err := slf.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				if err := slf.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) && !slf.restarting.Load() {
 					slf.isRunning = false
 					PushErrorMessage(slf, err, MessageErrorActionShutdown)
 				}
@@ -264,75 +327,29 @@ func (slf *Server) Run(addr string) error {
 				pattern = addr[index:]
 				slf.addr = slf.addr[:index]
 			}
-			var upgrade = websocket.Upgrader{
-				ReadBufferSize:  4096,
-				WriteBufferSize: 4096,
-				CheckOrigin: func(r *http.Request) bool {
-					return true
-				},
+			var handler, patterns = slf.websocketHandler(), []string{pattern}
+			if slf.websocketEndpoint != nil {
+				patterns = append(patterns, slf.websocketEndpoint.paths...)
+			}
+			for _, p := range patterns {
+				slf.websocketMux.Handle(p, handler)
 			}
-			http.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
-				ip := request.Header.Get("X-Real-IP")
-				ws, err := upgrade.Upgrade(writer, request, nil)
-				if err != nil {
-					return
-				}
-				if len(ip) == 0 {
-					addr := ws.RemoteAddr().String()
-					if index := strings.LastIndex(addr, ":"); index != -1 {
-						ip = addr[0:index]
-					}
-				}
-				if slf.websocketCompression > 0 {
-					_ = ws.SetCompressionLevel(slf.websocketCompression)
-				}
-				ws.EnableWriteCompression(slf.websocketWriteCompression)
-				conn := newWebsocketConn(slf, ws, ip)
-				for k, v := range request.URL.Query() {
-					if len(v) == 1 {
-						conn.SetData(k, v[0])
-					} else {
-						conn.SetData(k, v)
-					}
-				}
-				slf.OnConnectionOpenedEvent(conn)
-
-				defer func() {
-					if err := recover(); err != nil {
-						e, ok := err.(error)
-						if !ok {
-							e = fmt.Errorf("%v", err)
-						}
-						conn.Close(e)
-					}
-				}()
-				for !conn.IsClosed() {
-					if err := ws.SetReadDeadline(super.If(slf.websocketReadDeadline <= 0, times.Zero, time.Now().Add(slf.websocketReadDeadline))); err != nil {
-						panic(err)
-					}
-					messageType, packet, readErr := ws.ReadMessage()
-					if readErr != nil {
-						if conn.IsClosed() {
-							break
-						}
-						panic(readErr)
-					}
-					if len(slf.supportMessageTypes) > 0 && !slf.supportMessageTypes[messageType] {
-						panic(ErrWebsocketIllegalMessageType)
-					}
-					PushPacketMessage(slf, conn, messageType, packet)
-				}
-			})
 			go func() {
 				slf.isRunning = true
 				slf.OnStartBeforeEvent()
+				listener, err := slf.listen(string(NetworkTcp), slf.addr)
+				if err != nil {
+					slf.isRunning = false
+					PushErrorMessage(slf, err, MessageErrorActionShutdown)
+					return
+				}
 				if len(slf.certFile)+len(slf.keyFile) > 0 {
-					if err := http.ListenAndServeTLS(slf.addr, slf.certFile, slf.keyFile, nil); err != nil {
+					if err := slf.httpServer.ServeTLS(listener, slf.certFile, slf.keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) && !slf.restarting.Load() {
 						slf.isRunning = false
 						PushErrorMessage(slf, err, MessageErrorActionShutdown)
 					}
 				} else {
-					if err := http.ListenAndServe(slf.addr, nil); err != nil {
+					if err := slf.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) && !slf.restarting.Load() {
 						slf.isRunning = false
 						PushErrorMessage(slf, err, MessageErrorActionShutdown)
 					}
@@ -347,6 +364,10 @@ func (slf *Server) Run(addr string) error {
 	<-messageInitFinish
 	close(messageInitFinish)
 	messageInitFinish = nil
+	slf.runIdleDetect()
+	slf.runMetrics()
+	slf.runRecorder()
+	slf.runDeadlockDetect()
 	if slf.multiple == nil {
 		log.Info("Server", log.String(serverMark, "===================================================================="))
 		log.Info("Server", log.String(serverMark, "RunningInfo"),
@@ -355,15 +376,22 @@ func (slf *Server) Run(addr string) error {
 		)
 		log.Info("Server", log.String(serverMark, "===================================================================="))
 		slf.OnStartFinishEvent()
+		notifyHotRestartReady()
 		time.Sleep(time.Second)
 		if !slf.isShutdown.Load() {
 			slf.OnMessageReadyEvent()
 		}
 
-		signal.Notify(slf.systemSignal, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
-		select {
-		case <-slf.systemSignal:
+		signal.Notify(slf.systemSignal, append([]os.Signal{syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT}, hotRestartSignals...)...)
+		for sig := range slf.systemSignal {
+			if isHotRestartSignal(sig) {
+				if err := handoff(slf); err != nil {
+					log.Error("Server", log.Any("network", slf.network), log.String("listen", slf.addr), log.String("action", "hot restart"), log.Err(err))
+					continue
+				}
+			}
 			slf.shutdown(nil)
+			break
 		}
 
 		select {
@@ -447,11 +475,7 @@ func (slf *Server) Shutdown() {
 // shutdown 停止运行服务器
 func (slf *Server) shutdown(err error) {
 	slf.isShutdown.Store(true)
-	for slf.messageCounter.Load() > 0 {
-		log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
-			log.String("action", "shutdown"), log.String("state", "waiting"), log.Int64("message", slf.messageCounter.Load()))
-		time.Sleep(time.Second)
-	}
+	slf.drain()
 	if slf.multiple == nil {
 		slf.OnStopEvent()
 	}
@@ -463,6 +487,8 @@ func (slf *Server) shutdown(err error) {
 	if slf.ticker != nil {
 		slf.ticker.Release()
 	}
+	slf.stopMetrics()
+	slf.stopDeadlockDetect()
 	if slf.ants != nil {
 		slf.ants.Release()
 		slf.ants = nil
@@ -470,19 +496,32 @@ func (slf *Server) shutdown(err error) {
 	for _, cross := range slf.cross {
 		cross.Release()
 	}
+	if slf.tcpListener != nil {
+		_ = slf.tcpListener.Close()
+		for _, conn := range slf.online.Slice() {
+			conn.Close()
+		}
+	}
 	if slf.messageChannel != nil {
 		close(slf.messageChannel)
 		slf.messagePool.Close()
 		slf.messageChannel = nil
 	}
+	slf.stopRecorder()
 	if slf.shuntChannels != nil {
-		slf.shuntChannels.Range(func(key int64, c chan *Message) bool {
-			close(c)
+		slf.shuntChannels.Range(func(key int64, s *shunt) bool {
+			s.close(0)
 			return false
 		})
 		slf.shuntChannels.Clear()
 		slf.shuntChannels = nil
 	}
+	if slf.kcpListener != nil {
+		_ = slf.kcpListener.Close()
+	}
+	if slf.quicListener != nil {
+		_ = slf.quicListener.Close()
+	}
 	if slf.grpcServer != nil && slf.isRunning {
 		slf.grpcServer.GracefulStop()
 	}
@@ -559,43 +598,27 @@ func (slf *Server) GetMessageCount() int64 {
 }
 
 // ShuntChannelFreed 释放分流通道
+//   - 分流通道中已有的消息仍将被处理，之后匹配到该分流通道的数据包将创建新的分流通道
 func (slf *Server) ShuntChannelFreed(channelGuid int64) {
 	if slf.shuntChannels == nil {
 		return
 	}
-	channel, exist := slf.shuntChannels.GetExist(channelGuid)
-	if exist {
-		close(channel)
-		slf.shuntChannels.Delete(channelGuid)
-		slf.OnShuntChannelClosedEvent(channelGuid)
-	}
+	slf.freeShunt(channelGuid, 0)
 }
 
 // pushMessage 向服务器中写入特定类型的消息，需严格遵守消息属性要求
 func (slf *Server) pushMessage(message *Message) {
-	if slf.messagePool.IsClose() || !slf.OnMessageExecBeforeEvent(message) {
+	if slf.messagePool.IsClose() {
 		slf.messagePool.Release(message)
 		return
 	}
-	if slf.shuntChannels != nil && message.t == MessageTypePacket {
-		conn := message.attrs[0].(*Conn)
-		channelGuid, allowToCreate := slf.shuntMatcher(conn)
-		channel, exist := slf.shuntChannels.GetExist(channelGuid)
-		if !exist && allowToCreate {
-			channel = slf.channelGenerator(channelGuid)
-			slf.shuntChannels.Set(channelGuid, channel)
-			go func(channel chan *Message) {
-				for message := range channel {
-					slf.dispatchMessage(message)
-				}
-			}(channel)
-			defer slf.OnShuntChannelCreatedEvent(channelGuid)
-		}
-		if channel != nil {
-			slf.messageCounter.Add(1)
-			channel <- message
-			return
-		}
+	if !slf.OnMessageExecBeforeEvent(message) {
+		slf.metrics.cancel()
+		slf.messagePool.Release(message)
+		return
+	}
+	if slf.shuntChannels != nil && message.t == MessageTypePacket && slf.pushShunt(message) {
+		return
 	}
 	slf.messageCounter.Add(1)
 	slf.messageChannel <- message
@@ -615,27 +638,13 @@ func (slf *Server) low(message *Message, present time.Time, expect time.Duration
 	}
 }
 
-// dispatchMessage 消息分发
-func (slf *Server) dispatchMessage(msg *Message) {
-	var (
-		ctx    context.Context
-		cancel context.CancelFunc
-	)
-	if slf.deadlockDetect > 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), slf.deadlockDetect)
-		go func(ctx context.Context, msg *Message) {
-			select {
-			case <-ctx.Done():
-				if err := ctx.Err(); err == context.DeadlineExceeded {
-					log.Warn("Server", log.String("MessageType", messageNames[msg.t]), log.Any("SuspectedDeadlock", msg.attrs))
-				}
-			}
-		}(ctx, msg)
-	}
-
+// dispatchMessage 消息分发，goroutine 为分发消息的协程ID，用于死锁检测
+func (slf *Server) dispatchMessage(goroutine int64, msg *Message) {
+	watch := slf.deadlockDetect.begin(msg, goroutine)
 	present := time.Now()
 	defer func(msg *Message) {
-		if err := recover(); err != nil {
+		err := recover()
+		if err != nil {
 			stack := string(debug.Stack())
 			log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("MessageAttrs", msg.AttrsString()), log.Any("error", err), log.String("stack", stack))
 			fmt.Println(stack)
@@ -643,13 +652,17 @@ func (slf *Server) dispatchMessage(msg *Message) {
 				slf.OnMessageErrorEvent(msg, e)
 			}
 		}
+		if msg.epoch != nil {
+			msg.epoch.pending.Add(-1)
+		}
+		slf.deadlockDetect.end(watch)
 
 		if msg.t == MessageTypeAsync {
 			return
 		}
 
-		super.Handle(cancel)
 		slf.low(msg, present, time.Millisecond*100)
+		slf.metrics.message(msg.t, time.Since(present), err != nil)
 		slf.messageCounter.Add(-1)
 
 		if !slf.isShutdown.Load() {
@@ -661,9 +674,11 @@ func (slf *Server) dispatchMessage(msg *Message) {
 	switch msg.t {
 	case MessageTypePacket:
 		var conn, packet = msg.GetPacketMessageAttrs()
-		if !slf.OnConnectionPacketPreprocessEvent(conn, packet, func(newPacket []byte) { packet = newPacket }) {
-			slf.OnConnectionReceivePacketEvent(conn, packet)
+		if conn.isHandshaking() {
+			slf.handshakePacket(conn, packet)
+			break
 		}
+		slf.handlePacket(0, conn, packet)
 	case MessageTypeError:
 		var err, action = msg.GetErrorMessageAttrs()
 		switch action {
@@ -676,13 +691,18 @@ func (slf *Server) dispatchMessage(msg *Message) {
 		}
 	case MessageTypeCross:
 		slf.OnReceiveCrossPacketEvent(msg.GetCrossMessageAttrs())
+	case MessageTypeCrossRequest:
+		slf.OnReceiveCrossRequestEvent(msg.GetCrossRequestMessageAttrs())
 	case MessageTypeTicker:
 		msg.GetTickerMessageAttrs()()
 	case MessageTypeAsync:
 		handle, callback, cb := msg.GetAsyncMessageAttrs()
 		if err := slf.ants.Submit(func() {
+			// 异步消息由协程池中的任意协程执行，需要在执行时获取协程ID
+			watch := slf.deadlockDetect.begin(msg, slf.deadlockDetect.goroutine())
 			defer func() {
-				if err := recover(); err != nil {
+				err := recover()
+				if err != nil {
 					stack := string(debug.Stack())
 					log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err), log.String("stack", stack))
 					fmt.Println(stack)
@@ -690,8 +710,9 @@ func (slf *Server) dispatchMessage(msg *Message) {
 						slf.OnMessageErrorEvent(msg, e)
 					}
 				}
-				super.Handle(cancel)
+				slf.deadlockDetect.end(watch)
 				slf.low(msg, present, time.Second)
+				slf.metrics.message(msg.t, time.Since(present), err != nil)
 				slf.messageCounter.Add(-1)
 
 				if !slf.isShutdown.Load() {
@@ -0,0 +1,4 @@
+// Package session 提供了基于 server.Server 的会话层实现
+//   - 连接打开时将为连接签发会话恢复令牌，连接断开后会话将在宽限期内保留连接数据及断开期间写入的数据包
+//   - 新的连接可以通过令牌重新接入原有会话，适用于移动端短暂断线重连等场景
+package session
@@ -0,0 +1,8 @@
+package session
+
+import "errors"
+
+var (
+	ErrSessionNotFound = errors.New("the session does not exist or has expired")
+	ErrSessionRebind   = errors.New("the connection is already bound to this session")
+)
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"github.com/kercylan98/minotaur/utils/slice"
+)
+
+type (
+	SessionCreatedEventHandle func(manager *Manager, session *Session)
+	SessionResumedEventHandle func(manager *Manager, session *Session)
+	SessionExpiredEventHandle func(manager *Manager, session *Session)
+)
+
+func newEvents() *events {
+	return &events{
+		sessionCreatedEventHandles: slice.NewPriority[SessionCreatedEventHandle](),
+		sessionResumedEventHandles: slice.NewPriority[SessionResumedEventHandle](),
+		sessionExpiredEventHandles: slice.NewPriority[SessionExpiredEventHandle](),
+	}
+}
+
+type events struct {
+	sessionCreatedEventHandles *slice.Priority[SessionCreatedEventHandle]
+	sessionResumedEventHandles *slice.Priority[SessionResumedEventHandle]
+	sessionExpiredEventHandles *slice.Priority[SessionExpiredEventHandle]
+}
+
+// RegSessionCreatedEvent 在连接打开并创建新的会话时将立即执行被注册的事件处理函数
+//   - 通常应在该事件中将 Session.GetToken 下发至客户端
+func (slf *events) RegSessionCreatedEvent(handle SessionCreatedEventHandle, priority ...int) {
+	slf.sessionCreatedEventHandles.Append(handle, slice.GetValue(priority, 0))
+}
+
+func (slf *events) OnSessionCreatedEvent(manager *Manager, session *Session) {
+	slf.sessionCreatedEventHandles.RangeValue(func(index int, value SessionCreatedEventHandle) bool {
+		value(manager, session)
+		return true
+	})
+}
+
+// RegSessionResumedEvent 在新的连接重新接入已有会话时将立即执行被注册的事件处理函数
+func (slf *events) RegSessionResumedEvent(handle SessionResumedEventHandle, priority ...int) {
+	slf.sessionResumedEventHandles.Append(handle, slice.GetValue(priority, 0))
+}
+
+func (slf *events) OnSessionResumedEvent(manager *Manager, session *Session) {
+	slf.sessionResumedEventHandles.RangeValue(func(index int, value SessionResumedEventHandle) bool {
+		value(manager, session)
+		return true
+	})
+}
+
+// RegSessionExpiredEvent 在会话超出保留时长仍未恢复时将立即执行被注册的事件处理函数
+func (slf *events) RegSessionExpiredEvent(handle SessionExpiredEventHandle, priority ...int) {
+	slf.sessionExpiredEventHandles.Append(handle, slice.GetValue(priority, 0))
+}
+
+func (slf *events) OnSessionExpiredEvent(manager *Manager, session *Session) {
+	slf.sessionExpiredEventHandles.RangeValue(func(index int, value SessionExpiredEventHandle) bool {
+		value(manager, session)
+		return true
+	})
+}
@@ -0,0 +1,238 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenKey 连接数据中当前绑定会话的恢复令牌
+type tokenKey struct{}
+
+// NewManager 基于 server.Server 创建会话管理器
+//   - 会话管理器将注册服务器的连接打开、关闭及数据包写入事件，应在服务器运行前创建
+func NewManager(srv *server.Server, options ...Option) *Manager {
+	manager := &Manager{
+		events:     newEvents(),
+		srv:        srv,
+		grace:      DefaultGracePeriod,
+		replaySize: DefaultReplayBufferSize,
+		extractor: func(conn *server.Conn) string {
+			token, _ := conn.GetData(DefaultTokenKey).(string)
+			return token
+		},
+		tokens: make(map[string]*Session),
+	}
+	for _, option := range options {
+		option(manager)
+	}
+
+	srv.RegConnectionOpenedEvent(manager.onConnectionOpened, math.MinInt)
+	srv.RegConnectionClosedEvent(manager.onConnectionClosed, math.MinInt)
+	srv.RegConnectionWritePacketBeforeEvent(manager.onConnectionWritePacketBefore, math.MinInt)
+	srv.RegStopEvent(func(srv *server.Server) {
+		manager.Release()
+	}, math.MinInt)
+	return manager
+}
+
+// Manager 会话管理器
+type Manager struct {
+	*events
+	srv        *server.Server                 // 服务器
+	grace      time.Duration                  // 会话保留时长
+	replaySize int                            // 断开期间可缓存的数据包数量
+	extractor  func(conn *server.Conn) string // 恢复令牌获取函数
+	tokens     map[string]*Session            // 会话 [token]
+	rw         sync.RWMutex                   // 会话锁
+}
+
+// GetSession 获取连接所绑定的会话
+func (slf *Manager) GetSession(conn *server.Conn) *Session {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return slf.bound(conn)
+}
+
+// GetSessionWithToken 通过恢复令牌获取会话
+func (slf *Manager) GetSessionWithToken(token string) *Session {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return slf.tokens[token]
+}
+
+// GetSessionCount 获取会话数量，包含处于断开状态的会话
+func (slf *Manager) GetSessionCount() int {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return len(slf.tokens)
+}
+
+// Resume 将连接重新接入恢复令牌对应的会话
+//   - 连接在打开时创建的新会话将被丢弃，原有会话的连接数据将被复制到新的连接中（不会覆盖新连接中已存在的数据）
+//   - 会话断开期间写入的数据包将按顺序以相同的 websocket 消息类型重新写入新的连接
+//   - 当原有会话的连接依旧处于打开状态时，原有连接将被关闭
+func (slf *Manager) Resume(conn *server.Conn, token string) (*Session, error) {
+	slf.rw.Lock()
+	session, exist := slf.tokens[token]
+	if !exist || session.expired {
+		slf.rw.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	if session.conn == conn {
+		slf.rw.Unlock()
+		return nil, ErrSessionRebind
+	}
+	if fresh := slf.bound(conn); fresh != nil {
+		slf.discard(fresh)
+	}
+	if session.timer != nil {
+		session.timer.Stop()
+		session.timer = nil
+	}
+	prev := session.conn
+	session.conn = conn
+	session.suspended = false
+	session.resumed++
+	conn.SetData(tokenKey{}, session.token)
+	packets := session.replay
+	session.replay = nil
+	slf.rw.Unlock()
+
+	for k, v := range prev.GetDataAll() {
+		if conn.GetData(k) == nil {
+			conn.SetData(k, v)
+		}
+	}
+	if !prev.IsClosed() {
+		prev.Close(ErrSessionRebind)
+	}
+	var wst = conn.GetWST()
+	for _, r := range packets {
+		if r.wst != conn.GetWST() {
+			conn.SetWST(r.wst)
+		}
+		conn.Write(r.packet)
+	}
+	if conn.GetWST() != wst {
+		conn.SetWST(wst)
+	}
+	slf.OnSessionResumedEvent(slf, session)
+	return session, nil
+}
+
+// Release 释放所有会话，处于断开状态的会话将不会触发过期事件
+func (slf *Manager) Release() {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	for _, session := range slf.tokens {
+		if session.timer != nil {
+			session.timer.Stop()
+			session.timer = nil
+		}
+		session.expired = true
+	}
+	slf.tokens = make(map[string]*Session)
+}
+
+// onConnectionOpened 连接打开时尝试恢复会话，无法恢复时将创建新的会话
+func (slf *Manager) onConnectionOpened(srv *server.Server, conn *server.Conn) {
+	if token := slf.extractor(conn); len(token) > 0 {
+		if _, err := slf.Resume(conn, token); err == nil {
+			return
+		}
+	}
+
+	session := &Session{
+		token:     slf.generateToken(),
+		conn:      conn,
+		generated: time.Now(),
+	}
+	slf.rw.Lock()
+	slf.tokens[session.token] = session
+	conn.SetData(tokenKey{}, session.token)
+	slf.rw.Unlock()
+	slf.OnSessionCreatedEvent(slf, session)
+}
+
+// onConnectionClosed 连接关闭时将会话置为断开状态，并在保留时长后过期
+func (slf *Manager) onConnectionClosed(srv *server.Server, conn *server.Conn, err any) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	session := slf.bound(conn)
+	if session == nil || session.suspended {
+		return
+	}
+	session.suspended = true
+	session.closedAt = time.Now()
+	session.timer = time.AfterFunc(slf.grace, func() {
+		server.PushSystemMessage(slf.srv, func() {
+			slf.expire(session)
+		}, "SessionExpired")
+	})
+}
+
+// onConnectionWritePacketBefore 缓存会话断开期间写入的数据包
+func (slf *Manager) onConnectionWritePacketBefore(srv *server.Server, conn *server.Conn, packet []byte) []byte {
+	if slf.replaySize <= 0 || !conn.IsClosed() {
+		return packet
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	session := slf.bound(conn)
+	if session == nil || session.expired {
+		return packet
+	}
+	if len(session.replay) >= slf.replaySize {
+		session.replay = session.replay[1:]
+	}
+	session.replay = append(session.replay, replay{wst: conn.GetWST(), packet: packet})
+	return packet
+}
+
+// expire 使断开状态的会话过期
+func (slf *Manager) expire(session *Session) {
+	slf.rw.Lock()
+	if !session.suspended || session.expired || time.Since(session.closedAt) < slf.grace {
+		slf.rw.Unlock()
+		return
+	}
+	slf.discard(session)
+	slf.rw.Unlock()
+	slf.OnSessionExpiredEvent(slf, session)
+}
+
+// discard 丢弃会话，调用方需持有写锁
+func (slf *Manager) discard(session *Session) {
+	if session.timer != nil {
+		session.timer.Stop()
+		session.timer = nil
+	}
+	session.expired = true
+	session.replay = nil
+	delete(slf.tokens, session.token)
+}
+
+// bound 获取连接当前绑定的会话，调用方需持有读锁
+//   - 连接与会话通过连接数据中的恢复令牌关联，而非连接 ID，避免 NAT 后多个客户端远程地址相同时发生冲突
+//   - 被其他连接恢复后的原有连接依旧持有令牌，但不再视为绑定该会话
+func (slf *Manager) bound(conn *server.Conn) *Session {
+	token, _ := conn.GetData(tokenKey{}).(string)
+	if session, exist := slf.tokens[token]; exist && session.conn == conn {
+		return session
+	}
+	return nil
+}
+
+// generateToken 生成随机的恢复令牌
+func (slf *Manager) generateToken() string {
+	var buf = make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Error("Session", log.Err(err))
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,163 @@
+package session_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/session"
+	"testing"
+	"time"
+)
+
+func TestManager_Resume(t *testing.T) {
+	var created = make(chan *session.Session, 2)
+	var resumed = make(chan *session.Session, 1)
+	var expired = make(chan *session.Session, 1)
+	var closed = make(chan *server.Conn, 2)
+	var ready = make(chan struct{})
+	srv := server.New(server.NetworkWebsocket)
+	manager := session.NewManager(srv, session.WithGracePeriod(300*time.Millisecond))
+	manager.RegSessionCreatedEvent(func(manager *session.Manager, session *session.Session) {
+		created <- session
+	})
+	manager.RegSessionResumedEvent(func(manager *session.Manager, session *session.Session) {
+		resumed <- session
+	})
+	manager.RegSessionExpiredEvent(func(manager *session.Manager, session *session.Session) {
+		expired <- session
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- conn
+	})
+	go func() {
+		if err := srv.Run(":9983/ws"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	cli, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:9983/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s *session.Session
+	select {
+	case s = <-created:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session not created")
+	}
+	cli.Close()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed")
+	}
+	if !s.IsSuspended() {
+		t.Fatal("session not suspended")
+	}
+	// 断开期间写入的数据包将在恢复后按顺序以写入时的消息类型重新发送
+	s.GetConn().SetWST(server.WebsocketMessageTypeText)
+	s.Write([]byte("a"))
+	s.GetConn().SetWST(server.WebsocketMessageTypeBinary)
+	s.Write([]byte("b"))
+
+	cli, _, err = websocket.DefaultDialer.Dial("ws://127.0.0.1:9983/ws?"+session.DefaultTokenKey+"="+s.GetToken(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-resumed:
+		if r != s || r.IsSuspended() || r.GetResumedCount() != 1 {
+			t.Fatal(r.IsSuspended(), r.GetResumedCount())
+		}
+		if manager.GetSession(r.GetConn()) != s {
+			t.Fatal("session not bound to resumed connection")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session not resumed")
+	}
+	_ = cli.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, expect := range []struct {
+		messageType int
+		packet      string
+	}{{websocket.TextMessage, "a"}, {websocket.BinaryMessage, "b"}} {
+		messageType, packet, err := cli.ReadMessage()
+		if err != nil || messageType != expect.messageType || string(packet) != expect.packet {
+			t.Fatal(messageType, string(packet), expect, err)
+		}
+	}
+	if count := manager.GetSessionCount(); count != 1 {
+		t.Fatal(count)
+	}
+
+	cli.Close()
+	select {
+	case e := <-expired:
+		if e != s || !e.IsExpired() {
+			t.Fatal("unexpected expired session")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session not expired")
+	}
+	if manager.GetSessionWithToken(s.GetToken()) != nil || manager.GetSessionCount() != 0 {
+		t.Fatal("expired session not released")
+	}
+}
+
+func TestManager_SameRemoteAddress(t *testing.T) {
+	var started = make(chan struct{})
+	var created = make(chan *session.Session, 2)
+	var closed = make(chan struct{}, 1)
+	srv := server.New(server.NetworkNone)
+	manager := session.NewManager(srv)
+	manager.RegSessionCreatedEvent(func(manager *session.Manager, session *session.Session) {
+		created <- session
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- struct{}{}
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	// NAT 后的多个客户端可能拥有相同的远程地址
+	a := server.NewLoopbackConn(srv, "10.0.0.1:1000", func(packet []byte) {})
+	b := server.NewLoopbackConn(srv, "10.0.0.1:1000", func(packet []byte) {})
+	for _, conn := range []*server.Conn{a, b} {
+		srv.OnConnectionOpenedEvent(conn)
+		select {
+		case <-created:
+		case <-time.After(5 * time.Second):
+			t.Fatal("session not created")
+		}
+	}
+	sa, sb := manager.GetSession(a), manager.GetSession(b)
+	if sa == nil || sb == nil || sa == sb || manager.GetSessionCount() != 2 {
+		t.Fatal("sessions collided", sa, sb)
+	}
+
+	a.Close()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed")
+	}
+	if !sa.IsSuspended() || sb.IsSuspended() {
+		t.Fatal(sa.IsSuspended(), sb.IsSuspended())
+	}
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"time"
+)
+
+const (
+	DefaultGracePeriod      = 30 * time.Second
+	DefaultReplayBufferSize = 1024
+	DefaultTokenKey         = "resume_token"
+)
+
+// Option 会话管理器选项
+type Option func(manager *Manager)
+
+// WithGracePeriod 设置连接断开后会话的保留时长
+//   - 默认为 DefaultGracePeriod
+//   - 超出保留时长后会话将过期并触发 SessionExpiredEvent
+func WithGracePeriod(d time.Duration) Option {
+	return func(manager *Manager) {
+		if d > 0 {
+			manager.grace = d
+		}
+	}
+}
+
+// WithReplayBufferSize 设置会话断开期间可缓存的数据包数量
+//   - 默认为 DefaultReplayBufferSize
+//   - 超出数量时将丢弃最早的数据包，当 size <= 0 时表示不缓存
+func WithReplayBufferSize(size int) Option {
+	return func(manager *Manager) {
+		manager.replaySize = size
+	}
+}
+
+// WithTokenExtractor 设置在连接打开时获取恢复令牌的函数
+//   - 默认将通过连接数据中 DefaultTokenKey 的值获取，适用于 Websocket 通过 url 参数携带令牌的情况
+//   - 当无法在连接打开时获取令牌时（例如通过登录数据包携带），可通过 Manager.Resume 函数手动恢复会话
+func WithTokenExtractor(extractor func(conn *server.Conn) string) Option {
+	return func(manager *Manager) {
+		manager.extractor = extractor
+	}
+}
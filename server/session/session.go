@@ -0,0 +1,78 @@
+package session
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"time"
+)
+
+// Session 会话，在连接断开后的宽限期内依旧保留连接数据
+type Session struct {
+	token     string       // 恢复令牌
+	conn      *server.Conn // 当前绑定的连接
+	suspended bool         // 是否处于断开状态
+	expired   bool         // 是否已过期
+	generated time.Time    // 创建时间
+	closedAt  time.Time    // 最近一次断开时间
+	resumed   int          // 恢复次数
+	replay    []replay     // 断开期间写入的数据包
+	timer     *time.Timer  // 过期定时器
+}
+
+// replay 断开期间写入的数据包
+type replay struct {
+	wst    int    // websocket 消息类型
+	packet []byte // 数据包
+}
+
+// GetToken 获取会话恢复令牌
+func (slf *Session) GetToken() string {
+	return slf.token
+}
+
+// GetConn 获取会话当前绑定的连接
+//   - 当会话处于断开状态时，返回的为已经关闭的连接，依旧可以通过其获取连接数据
+func (slf *Session) GetConn() *server.Conn {
+	return slf.conn
+}
+
+// GetData 获取会话连接数据
+func (slf *Session) GetData(key any) any {
+	return slf.conn.GetData(key)
+}
+
+// SetData 设置会话连接数据，该数据将在会话过期前始终存在
+func (slf *Session) SetData(key, value any) *Session {
+	slf.conn.SetData(key, value)
+	return slf
+}
+
+// IsSuspended 会话是否处于断开等待恢复的状态
+func (slf *Session) IsSuspended() bool {
+	return slf.suspended
+}
+
+// IsExpired 会话是否已过期
+func (slf *Session) IsExpired() bool {
+	return slf.expired
+}
+
+// GetClosedTime 获取会话最近一次断开的时间
+func (slf *Session) GetClosedTime() time.Time {
+	return slf.closedAt
+}
+
+// GetCreatedTime 获取会话创建时间
+func (slf *Session) GetCreatedTime() time.Time {
+	return slf.generated
+}
+
+// GetResumedCount 获取会话被恢复的次数
+func (slf *Session) GetResumedCount() int {
+	return slf.resumed
+}
+
+// Write 向会话写入数据包
+//   - 当会话处于断开状态时，数据包将被缓存并在会话恢复后以相同的 websocket 消息类型重新发送
+func (slf *Session) Write(packet []byte, callback ...func(err error)) {
+	slf.conn.Write(packet, callback...)
+}
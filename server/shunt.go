@@ -0,0 +1,249 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shunt 分流通道
+type shunt struct {
+	guid       int64          // 分流通道 GUID
+	channel    chan *Message  // 分流通道
+	l          sync.RWMutex   // 关闭状态锁
+	senders    sync.WaitGroup // 正在向通道写入消息的数量
+	closed     bool           // 是否已关闭
+	active     atomic.Int64   // 最近一次处理消息的时间
+	processed  atomic.Int64   // 已处理的消息数量
+	latency    atomic.Int64   // 消息在通道中等待的总耗时
+	maxLatency atomic.Int64   // 消息在通道中等待的最长耗时
+}
+
+// shuntEpoch 连接绑定分流通道的批次，用于在重新绑定时确保消息的处理顺序
+type shuntEpoch struct {
+	pending atomic.Int64 // 该批次中尚未处理完成的消息数量
+}
+
+// connShunt 连接的分流通道绑定状态
+type connShunt struct {
+	l     sync.Mutex  // 绑定状态锁
+	guid  int64       // 绑定的分流通道 GUID
+	bound bool        // 是否已通过 Server.ShuntRebind 绑定
+	epoch *shuntEpoch // 当前批次
+}
+
+// ShuntStats 分流通道统计信息
+type ShuntStats struct {
+	Guid       int64         // 分流通道 GUID
+	Depth      int           // 通道中等待处理的消息数量
+	Capacity   int           // 通道容量
+	Processed  int64         // 已处理的消息数量
+	AvgLatency time.Duration // 消息在通道中等待的平均耗时
+	MaxLatency time.Duration // 消息在通道中等待的最长耗时
+	LastActive time.Time     // 最近一次处理消息的时间
+}
+
+// push 向分流通道写入消息，当分流通道已关闭时返回 false
+func (slf *shunt) push(message *Message) bool {
+	slf.l.RLock()
+	if slf.closed {
+		slf.l.RUnlock()
+		return false
+	}
+	slf.senders.Add(1)
+	slf.l.RUnlock()
+	defer slf.senders.Done()
+	message.since = time.Now().UnixNano()
+	slf.channel <- message
+	return true
+}
+
+// close 关闭分流通道，已写入的消息仍将被处理
+//   - 当 idle 大于 0 时，仅在分流通道为空且空闲超过 idle 时关闭，返回是否关闭成功
+func (slf *shunt) close(idle time.Duration) bool {
+	if idle > 0 {
+		if !slf.l.TryLock() {
+			return false
+		}
+	} else {
+		slf.l.Lock()
+	}
+	defer slf.l.Unlock()
+	if slf.closed || (idle > 0 && (len(slf.channel) > 0 || time.Since(time.Unix(0, slf.active.Load())) < idle)) {
+		return false
+	}
+	slf.closed = true
+	go func() {
+		slf.senders.Wait()
+		close(slf.channel)
+	}()
+	return true
+}
+
+// stats 获取分流通道统计信息
+func (slf *shunt) stats() ShuntStats {
+	stats := ShuntStats{
+		Guid:       slf.guid,
+		Depth:      len(slf.channel),
+		Capacity:   cap(slf.channel),
+		Processed:  slf.processed.Load(),
+		MaxLatency: time.Duration(slf.maxLatency.Load()),
+		LastActive: time.Unix(0, slf.active.Load()),
+	}
+	if stats.Processed > 0 {
+		stats.AvgLatency = time.Duration(slf.latency.Load() / stats.Processed)
+	}
+	return stats
+}
+
+// runShunt 运行分流通道，当设置了 WithShuntIdleTimeout 时将定期检查并释放空闲的分流通道
+func (slf *Server) runShunt(s *shunt) {
+	var idle <-chan time.Time
+	if slf.shuntIdle > 0 {
+		ticker := time.NewTicker(slf.shuntIdle / 2)
+		defer ticker.Stop()
+		idle = ticker.C
+	}
+	goroutine := slf.deadlockDetect.goroutine()
+	for {
+		select {
+		case message, ok := <-s.channel:
+			if !ok {
+				return
+			}
+			now := time.Now().UnixNano()
+			latency := now - message.since
+			s.active.Store(now)
+			s.processed.Add(1)
+			s.latency.Add(latency)
+			for m := s.maxLatency.Load(); latency > m && !s.maxLatency.CompareAndSwap(m, latency); m = s.maxLatency.Load() {
+			}
+			slf.dispatchMessage(goroutine, message)
+		case <-idle:
+			slf.freeShunt(s.guid, slf.shuntIdle)
+		}
+	}
+}
+
+// freeShunt 释放分流通道，idle 大于 0 时仅释放空闲超过 idle 的分流通道
+func (slf *Server) freeShunt(guid int64, idle time.Duration) {
+	var freed bool
+	slf.shuntChannels.Atom(func(m map[int64]*shunt) {
+		if s, exist := m[guid]; exist && s.close(idle) {
+			delete(m, guid)
+			freed = true
+		}
+	})
+	if freed {
+		slf.OnShuntChannelClosedEvent(guid)
+	}
+}
+
+// getShunt 获取分流通道，当分流通道不存在且 allowToCreate 为 true 时将创建新的分流通道
+func (slf *Server) getShunt(guid int64, allowToCreate bool) *shunt {
+	var s *shunt
+	var created bool
+	slf.shuntChannels.Atom(func(m map[int64]*shunt) {
+		var exist bool
+		if s, exist = m[guid]; !exist && allowToCreate {
+			s = &shunt{guid: guid, channel: slf.channelGenerator(guid)}
+			s.active.Store(time.Now().UnixNano())
+			m[guid] = s
+			created = true
+		}
+	})
+	if created {
+		go slf.runShunt(s)
+		slf.OnShuntChannelCreatedEvent(guid)
+	}
+	return s
+}
+
+// pushShunt 将数据包消息写入连接所匹配或绑定的分流通道，当没有可用的分流通道时返回 false
+func (slf *Server) pushShunt(message *Message) bool {
+	conn := message.attrs[0].(*Conn)
+	for {
+		cs := &conn.shunt
+		cs.l.Lock()
+		guid, allowToCreate := cs.guid, cs.bound
+		if !cs.bound {
+			guid, allowToCreate = slf.shuntMatcher(conn)
+		}
+		if cs.epoch == nil {
+			cs.epoch = new(shuntEpoch)
+		}
+		epoch := cs.epoch
+		epoch.pending.Add(1)
+		cs.l.Unlock()
+
+		s := slf.getShunt(guid, allowToCreate)
+		if s == nil {
+			epoch.pending.Add(-1)
+			return false
+		}
+		message.epoch = epoch
+		slf.messageCounter.Add(1)
+		if s.push(message) {
+			return true
+		}
+		slf.messageCounter.Add(-1)
+		message.epoch = nil
+		epoch.pending.Add(-1)
+	}
+}
+
+// ShuntRebind 将连接绑定到特定的分流通道，绑定后该连接的数据包将不再通过 WithShunt 的 shuntMatcher 进行匹配
+//   - 当分流通道不存在时将自动创建
+//   - 绑定前已进入其他分流通道的数据包将优先处理完成，随后才会在新的分流通道中处理该连接后续的数据包，在此期间新的分流通道将被阻塞
+//   - 当服务器未通过 WithShunt 创建时将发生 panic
+func (slf *Server) ShuntRebind(conn *Conn, guid int64) {
+	if slf.shuntChannels == nil {
+		panic(ErrNoSupportShunt)
+	}
+	cs := &conn.shunt
+	cs.l.Lock()
+	defer cs.l.Unlock()
+	if cs.bound && cs.guid == guid {
+		return
+	}
+	prev := cs.epoch
+	cs.guid, cs.bound, cs.epoch = guid, true, new(shuntEpoch)
+	if prev == nil {
+		return
+	}
+
+	barrier := slf.messagePool.Get()
+	barrier.t = MessageTypeSystem
+	barrier.attrs = []any{func() {
+		for prev.pending.Load() > 0 && !slf.isShutdown.Load() {
+			time.Sleep(time.Millisecond)
+		}
+	}, "ShuntRebind"}
+	barrier.epoch = cs.epoch
+	cs.epoch.pending.Add(1)
+	for {
+		s := slf.getShunt(guid, true)
+		slf.messageCounter.Add(1)
+		if s.push(barrier) {
+			return
+		}
+		slf.messageCounter.Add(-1)
+	}
+}
+
+// GetShuntStats 获取所有分流通道的统计信息，按 GUID 排序
+func (slf *Server) GetShuntStats() []ShuntStats {
+	if slf.shuntChannels == nil {
+		return nil
+	}
+	var stats []ShuntStats
+	slf.shuntChannels.Range(func(guid int64, s *shunt) bool {
+		stats = append(stats, s.stats())
+		return false
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Guid < stats[j].Guid
+	})
+	return stats
+}
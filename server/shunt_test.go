@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServer_ShuntRebind(t *testing.T) {
+	var l sync.Mutex
+	var order []string
+	var closed = make(chan int64, 2)
+	var started = make(chan struct{})
+	srv := server.New(server.NetworkNone,
+		server.WithShunt(func(guid int64) chan *server.Message {
+			return make(chan *server.Message, 16)
+		}, func(conn *server.Conn) (guid int64, allowToCreate bool) {
+			return 1, true
+		}),
+		server.WithShuntIdleTimeout(200*time.Millisecond),
+	)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if string(packet) == "1" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		l.Lock()
+		order = append(order, string(packet))
+		l.Unlock()
+	})
+	srv.RegShuntChannelCloseEvent(func(srv *server.Server, guid int64) {
+		closed <- guid
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(started)
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	<-started
+
+	conn := server.NewLoopbackConn(srv, "player", func(packet []byte) {})
+	server.PushPacketMessage(srv, conn, 0, []byte("1"))
+	server.PushPacketMessage(srv, conn, 0, []byte("2"))
+	srv.ShuntRebind(conn, 2)
+	server.PushPacketMessage(srv, conn, 0, []byte("3"))
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		l.Lock()
+		n := len(order)
+		l.Unlock()
+		if n == 3 {
+			break
+		}
+	}
+
+	l.Lock()
+	if len(order) != 3 || order[0] != "1" || order[1] != "2" || order[2] != "3" {
+		t.Fatal(order)
+	}
+	l.Unlock()
+	stats := srv.GetShuntStats()
+	if len(stats) != 2 || stats[0].Guid != 1 || stats[0].Processed != 2 || stats[1].Processed != 2 || stats[0].MaxLatency < stats[0].AvgLatency {
+		t.Fatal(stats)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("shunt channel not released")
+		}
+	}
+	if stats = srv.GetShuntStats(); len(stats) != 0 {
+		t.Fatal(stats)
+	}
+}
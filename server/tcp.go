@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// isListenerTCP 是否为基于 net.Listener 运行的 Tcp 网络
+//   - gnet 无法在保留已有连接的同时单独停止侦听，因此启用热重启时 Tcp 网络将基于 net.Listener 运行，以便将侦听器交接给新的进程
+func (slf *Server) isListenerTCP() bool {
+	switch slf.network {
+	case NetworkTcp, NetworkTcp4, NetworkTcp6:
+		return slf.hotRestart != nil
+	}
+	return false
+}
+
+// serveTCP 接受 TCP 连接，直到侦听器被关闭
+func (slf *Server) serveTCP(listener net.Listener) {
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			if slf.isShutdown.Load() || slf.restarting.Load() || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go slf.acceptTCP(c)
+	}
+}
+
+// acceptTCP 将 TCP 连接作为服务器连接打开，并持续读取数据直到连接关闭
+func (slf *Server) acceptTCP(c net.Conn) {
+	if packet, refused := slf.refuse(); refused {
+		if len(packet) > 0 {
+			_, _ = c.Write(packet)
+		}
+		_ = c.Close()
+		return
+	}
+	if !slf.acquireIP(ipOf(c.RemoteAddr())) {
+		_ = c.Close()
+		return
+	}
+	conn := newTCPConn(slf, c)
+	slf.openConn(conn, func() {
+		slf.OnConnectionOpenedEvent(conn)
+		slf.OnConnectionOpenedAfterEvent(conn)
+	})
+
+	defer func() {
+		if err := recover(); err != nil {
+			e, ok := err.(error)
+			if !ok {
+				e = fmt.Errorf("%v", err)
+			}
+			conn.Close(e)
+		}
+	}()
+	buf := make([]byte, 4096)
+	for !conn.IsClosed() {
+		n, err := c.Read(buf)
+		if err != nil {
+			if conn.IsClosed() || slf.isShutdown.Load() {
+				break
+			}
+			panic(err)
+		}
+		if err = conn.receive(0, buf[:n]); err != nil {
+			panic(err)
+		}
+	}
+}
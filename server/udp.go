@@ -0,0 +1,122 @@
+package server
+
+import (
+	"github.com/panjf2000/gnet"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultUDPSessionIdleTimeout = time.Minute // 默认 UDP 会话空闲过期时间
+	DefaultUDPMaxSessions        = 65536       // 默认 UDP 会话数量上限
+)
+
+// udpSessions 基于远程地址的虚拟 UDP 会话
+//   - gnet 在 UDP 模式下不会触发 OnOpened 及 OnClosed，并且每个数据报都将使用临时的连接，因此需要通过远程地址维护会话
+type udpSessions struct {
+	sessions map[string]*Conn // 远程地址对应的会话
+	l        sync.Mutex       // 会话锁
+}
+
+// isUDPNetwork 是否为 UDP 网络类型
+func isUDPNetwork(network Network) bool {
+	switch network {
+	case NetworkUdp, NetworkUdp4, NetworkUdp6:
+		return true
+	}
+	return false
+}
+
+// isUDPSession 连接是否为虚拟 UDP 会话
+func (slf *Conn) isUDPSession() bool {
+	return slf.gn != nil && isUDPNetwork(slf.server.network)
+}
+
+// udpSession 获取数据报对应的会话，当会话不存在时将创建新的会话并打开连接，连接被拒绝或会话数量达到上限时将返回 nil
+//   - c 仅在 React 期间有效，其远程地址将在 React 返回后被 gnet 回收，因此会话需要复制远程地址
+//   - 会话将保留首个数据报的 c 用于通过 SendTo 向远程地址回复数据
+func (slf *gNet) udpSession(c gnet.Conn) *Conn {
+	remote, ok := c.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	addr := &net.UDPAddr{IP: append(net.IP(nil), remote.IP...), Port: remote.Port, Zone: remote.Zone}
+	key := addr.String()
+
+	slf.udp.l.Lock()
+	if conn, exist := slf.udp.sessions[key]; exist {
+		slf.udp.l.Unlock()
+		return conn
+	}
+	if packet, refused := slf.refuse(); refused {
+		slf.udp.l.Unlock()
+		if len(packet) > 0 {
+			_ = c.SendTo(packet)
+		}
+		return nil
+	}
+	if len(slf.udp.sessions) >= slf.udpMaxSessions() {
+		slf.udp.l.Unlock()
+		return nil
+	}
+	if !slf.acquireIP(ipOf(addr)) {
+		slf.udp.l.Unlock()
+		return nil
+	}
+	conn := newGNetConn(slf.Server, c)
+	conn.remoteAddr = addr
+	if slf.udp.sessions == nil {
+		slf.udp.sessions = map[string]*Conn{}
+	}
+	slf.udp.sessions[key] = conn
+	slf.udp.l.Unlock()
+
+	slf.openConn(conn, func() {
+		slf.OnConnectionOpenedEvent(conn)
+	})
+	return conn
+}
+
+// freeUDPSession 在连接关闭时释放会话
+func (slf *gNet) freeUDPSession(conn *Conn) {
+	slf.udp.l.Lock()
+	defer slf.udp.l.Unlock()
+	if slf.udp.sessions[conn.GetID()] == conn {
+		delete(slf.udp.sessions, conn.GetID())
+	}
+}
+
+// udpSessionIdleTimeout 获取 UDP 会话空闲过期时间
+func (slf *Server) udpSessionIdleTimeout() time.Duration {
+	if slf.udpSessionIdle <= 0 {
+		return DefaultUDPSessionIdleTimeout
+	}
+	return slf.udpSessionIdle
+}
+
+// udpMaxSessions 获取 UDP 会话数量上限
+func (slf *Server) udpMaxSessions() int {
+	if slf.udpSessionMax <= 0 {
+		return DefaultUDPMaxSessions
+	}
+	return slf.udpSessionMax
+}
+
+// expireUDPSessions 关闭超过空闲过期时间的会话，返回下一次检查的间隔
+func (slf *gNet) expireUDPSessions() time.Duration {
+	var timeout = slf.udpSessionIdleTimeout()
+	var now = time.Now()
+	var expired []*Conn
+	slf.udp.l.Lock()
+	for _, conn := range slf.udp.sessions {
+		if now.Sub(conn.GetLastReceiveTime()) >= timeout {
+			expired = append(expired, conn)
+		}
+	}
+	slf.udp.l.Unlock()
+	for _, conn := range expired {
+		conn.Close(ErrUDPSessionExpired)
+	}
+	return max(timeout/2, 10*time.Millisecond)
+}
@@ -0,0 +1,162 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithUDPSessionIdleTimeout(t *testing.T) {
+	var opened = make(chan string, 4)
+	var closed = make(chan any, 4)
+	srv := server.New(server.NetworkUdp, server.WithUDPSessionIdleTimeout(300*time.Millisecond, 2))
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened <- conn.GetID()
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(append([]byte(conn.GetID()+":"), packet...))
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+		closed <- err
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9984"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	var peers []net.Conn
+	for i := 0; i < 2; i++ {
+		peer, err := net.Dial("udp", "127.0.0.1:9984")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer peer.Close()
+		peers = append(peers, peer)
+	}
+	for _, peer := range peers {
+		for i := 0; i < 2; i++ {
+			_, _ = peer.Write([]byte("ping"))
+			var buf = make([]byte, 64)
+			_ = peer.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, err := peer.Read(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expect := peer.LocalAddr().String() + ":ping"; string(buf[:n]) != expect {
+				t.Fatal(string(buf[:n]), expect)
+			}
+		}
+	}
+	for _, peer := range peers {
+		select {
+		case id := <-opened:
+			if id != peers[0].LocalAddr().String() && id != peers[1].LocalAddr().String() {
+				t.Fatal(id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("opened timeout", peer.LocalAddr())
+		}
+	}
+	if count := srv.GetOnlineCount(); count != 2 {
+		t.Fatal(count)
+	}
+
+	// 会话数量达到上限后，来自新地址的数据报将被丢弃
+	overflow, err := net.Dial("udp", "127.0.0.1:9984")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer overflow.Close()
+	_, _ = overflow.Write([]byte("ping"))
+	_ = overflow.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, err := overflow.Read(make([]byte, 64)); err == nil {
+		t.Fatal("session limit exceeded", n)
+	}
+
+	for range peers {
+		select {
+		case err := <-closed:
+			if e, ok := err.(error); !ok || !errors.Is(e, server.ErrUDPSessionExpired) {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("session not expired")
+		}
+	}
+	for deadline := time.Now().Add(time.Second); srv.GetOnlineCount() != 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := srv.GetOnlineCount(); count != 0 {
+		t.Fatal(count)
+	}
+
+	_, _ = peers[0].Write([]byte("again"))
+	select {
+	case id := <-opened:
+		if id != peers[0].LocalAddr().String() {
+			t.Fatal(id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session not recreated")
+	}
+}
+
+func TestWithUDPSessionIdleTimeout_PacketCodec(t *testing.T) {
+	srv := server.New(server.NetworkUdp, server.WithPacketCodec(server.NewLengthFieldCodec()))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var ready = make(chan struct{})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		if err := srv.Run(":9979"); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer srv.Shutdown()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	peer, err := net.Dial("udp", "127.0.0.1:9979")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	codec := server.NewLengthFieldCodec()
+	a, _ := codec.Encode([]byte("a"))
+	b, _ := codec.Encode([]byte("b"))
+	c, _ := codec.Encode([]byte("c"))
+	// 数据报中不完整的剩余数据将被丢弃，不会与下一个数据报拼接
+	for _, datagram := range [][]byte{append(a, b[:3]...), c} {
+		_, _ = peer.Write(datagram)
+	}
+	for _, expect := range []string{"a", "c"} {
+		var buf = make([]byte, 64)
+		_ = peer.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := peer.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packet, _, err := codec.Decode(buf[:n]); err != nil || string(packet) != expect {
+			t.Fatal(string(packet), expect, err)
+		}
+	}
+}
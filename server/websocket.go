@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/utils/super"
+	"github.com/kercylan98/minotaur/utils/times"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// wsEndpoint Websocket额外的升级路径及升级器
+type wsEndpoint struct {
+	paths         []string                      // 额外的升级路径
+	upgrader      *websocket.Upgrader           // 升级器
+	authenticator WebsocketUpgradeAuthenticator // 升级前鉴权
+}
+
+// WebsocketServeMux 当网络类型为 NetworkWebsocket 时将被允许获取 Websocket 服务器所使用的路由器，否则将会发生 panic
+//   - 可通过该路由器在 Websocket 服务相同的端口上注册健康检查等普通 HTTP 路由
+//   - 注册的路由不应与 Websocket 升级路径重复，否则将在服务器运行时发生 panic
+func (slf *Server) WebsocketServeMux() *http.ServeMux {
+	if slf.websocketMux == nil {
+		panic(ErrNetworkOnlySupportWebsocket)
+	}
+	return slf.websocketMux
+}
+
+// websocketHandler 获取处理 Websocket 升级请求的 http.Handler
+func (slf *Server) websocketHandler() http.HandlerFunc {
+	var upgrader *websocket.Upgrader
+	var authenticator WebsocketUpgradeAuthenticator
+	if slf.websocketEndpoint != nil {
+		upgrader = slf.websocketEndpoint.upgrader
+		authenticator = slf.websocketEndpoint.authenticator
+	}
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		}
+	}
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ip := request.Header.Get("X-Real-IP")
+		if len(ip) == 0 {
+			addr := request.RemoteAddr
+			if index := strings.LastIndex(addr, ":"); index != -1 {
+				ip = addr[0:index]
+			}
+		}
+		if packet, refused := slf.refuse(); refused {
+			if len(packet) == 0 {
+				http.Error(writer, ErrServerMaintenance.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if ws, err := upgrader.Upgrade(writer, request, nil); err == nil {
+				_ = ws.WriteMessage(WebsocketMessageTypeBinary, packet)
+				_ = ws.WriteMessage(WebsocketMessageTypeClose, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, ErrServerMaintenance.Error()))
+				_ = ws.Close()
+			}
+			return
+		}
+		if authenticator != nil {
+			if status, err := authenticator(request); err != nil {
+				if status == 0 {
+					status = http.StatusUnauthorized
+				}
+				http.Error(writer, err.Error(), status)
+				return
+			}
+		}
+		if !slf.acquireIP(ip) {
+			http.Error(writer, ErrConnectionLimitPerIP.Error(), http.StatusTooManyRequests)
+			return
+		}
+		ws, err := upgrader.Upgrade(writer, request, nil)
+		if err != nil {
+			slf.releaseIP(ip)
+			return
+		}
+		if slf.websocketCompression > 0 {
+			_ = ws.SetCompressionLevel(slf.websocketCompression)
+		}
+		ws.EnableWriteCompression(slf.websocketWriteCompression)
+		conn := newWebsocketConn(slf, ws, ip, request)
+		for k, v := range request.URL.Query() {
+			if len(v) == 1 {
+				conn.SetData(k, v[0])
+			} else {
+				conn.SetData(k, v)
+			}
+		}
+		slf.openConn(conn, func() {
+			slf.OnConnectionOpenedEvent(conn)
+		})
+
+		defer func() {
+			if err := recover(); err != nil {
+				e, ok := err.(error)
+				if !ok {
+					e = fmt.Errorf("%v", err)
+				}
+				conn.Close(e)
+			}
+		}()
+		for !conn.IsClosed() {
+			if err := ws.SetReadDeadline(super.If(slf.websocketReadDeadline <= 0, times.Zero, time.Now().Add(slf.websocketReadDeadline))); err != nil {
+				panic(err)
+			}
+			messageType, packet, readErr := ws.ReadMessage()
+			if readErr != nil {
+				if conn.IsClosed() {
+					break
+				}
+				panic(readErr)
+			}
+			if len(slf.supportMessageTypes) > 0 && !slf.supportMessageTypes[messageType] {
+				panic(ErrWebsocketIllegalMessageType)
+			}
+			slf.metrics.received(len(packet))
+			conn.pushPacket(messageType, packet)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServer_WebsocketServeMux(t *testing.T) {
+	var paths = make(chan string, 2)
+	var ready = make(chan struct{}, 2)
+	var servers []*server.Server
+	for _, addr := range []string{":9990", ":9991"} {
+		srv := server.New(server.NetworkWebsocket, server.WithWebsocketPaths("/extra"))
+		srv.WebsocketServeMux().HandleFunc("/health", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		})
+		srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {})
+		srv.RegMessageReadyEvent(func(srv *server.Server) {
+			ready <- struct{}{}
+		})
+		srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+			paths <- conn.GetWebsocketRequest().URL.Path
+		})
+		go func(srv *server.Server, addr string) {
+			if err := srv.Run(addr + "/ws"); err != nil {
+				t.Error(err)
+			}
+		}(srv, addr)
+		servers = append(servers, srv)
+	}
+	for range servers {
+		select {
+		case <-ready:
+		case <-time.After(5 * time.Second):
+			t.Fatal("server not ready")
+		}
+	}
+
+	for _, url := range []string{"ws://127.0.0.1:9990/ws", "ws://127.0.0.1:9991/extra"} {
+		cli := client.NewWebsocket(url)
+		if err := cli.Run(); err != nil {
+			t.Fatal(err)
+		}
+		defer cli.Close()
+	}
+	resp, err := http.Get("http://127.0.0.1:9991/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatal(resp.StatusCode)
+	}
+
+	var received = map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case path := <-paths:
+			received[path] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	if !received["/ws"] || !received["/extra"] {
+		t.Fatal(received)
+	}
+	for _, srv := range servers {
+		srv.Shutdown()
+	}
+}
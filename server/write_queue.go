@@ -0,0 +1,172 @@
+package server
+
+import (
+	"time"
+)
+
+const (
+	WriteQueuePolicyBlock      WriteQueuePolicy = iota + 1 // 写入队列策略：阻塞写入直到队列可用，超时后丢弃当前写入的数据包
+	WriteQueuePolicyDropOldest                             // 写入队列策略：丢弃队列中最早的数据包
+	WriteQueuePolicyDropNewest                             // 写入队列策略：丢弃当前写入的数据包
+	WriteQueuePolicyClose                                  // 写入队列策略：关闭连接
+)
+
+var writeQueuePolicyNames = map[WriteQueuePolicy]string{
+	WriteQueuePolicyBlock:      "Block",
+	WriteQueuePolicyDropOldest: "DropOldest",
+	WriteQueuePolicyDropNewest: "DropNewest",
+	WriteQueuePolicyClose:      "Close",
+}
+
+// WriteQueuePolicy 连接写入队列已满时的策略
+type WriteQueuePolicy byte
+
+func (slf WriteQueuePolicy) String() string {
+	return writeQueuePolicyNames[slf]
+}
+
+// writeQueue 连接写入队列配置
+type writeQueue struct {
+	size    int              // 队列可容纳的数据包数量
+	bytes   int64            // 队列可容纳的字节数
+	policy  WriteQueuePolicy // 队列已满时的策略
+	timeout time.Duration    // 阻塞写入的超时时间
+}
+
+// getWriteQueueSize 获取连接写入队列可容纳的数据包数量
+func (slf *Server) getWriteQueueSize() int {
+	if slf.writeQueue == nil {
+		return DefaultConnWriteQueueSize
+	}
+	return slf.writeQueue.size
+}
+
+// GetPendingBytes 获取连接写入队列中尚未写入的字节数
+func (slf *Conn) GetPendingBytes() int64 {
+	return slf.pendingBytes.Load()
+}
+
+// GetPendingPackets 获取连接写入队列中尚未写入的数据包数量
+func (slf *Conn) GetPendingPackets() int {
+	return len(slf.packets)
+}
+
+// overflow 检查写入特定大小的数据包后是否超出写入队列的字节数限制
+//   - 当队列中没有待写入的数据时，单个超出限制的数据包将被允许写入
+func (slf *Conn) overflow(size int) bool {
+	q := slf.server.writeQueue
+	if q == nil || q.bytes <= 0 {
+		return false
+	}
+	pending := slf.pendingBytes.Load()
+	return pending > 0 && pending+int64(size) > q.bytes
+}
+
+// offer 尝试以非阻塞的方式将数据包放入写入队列
+func (slf *Conn) offer(cp *connPacket) bool {
+	size := int64(len(cp.packet))
+	slf.pendingBytes.Add(size)
+	select {
+	case slf.packets <- cp:
+		return true
+	default:
+		slf.pendingBytes.Add(-size)
+		return false
+	}
+}
+
+// enqueue 将数据包放入写入队列，当队列已满时将根据写入队列配置执行对应的策略
+//   - 当返回 closing 为 true 时，表示调用方应在释放锁后关闭连接
+func (slf *Conn) enqueue(cp *connPacket) (closing bool) {
+	size := len(cp.packet)
+	if !slf.overflow(size) && slf.offer(cp) {
+		return false
+	}
+	var policy, timeout = WriteQueuePolicyBlock, time.Duration(0)
+	if q := slf.server.writeQueue; q != nil {
+		policy, timeout = q.policy, q.timeout
+	}
+	slf.slow(policy)
+	switch policy {
+	case WriteQueuePolicyBlock:
+		if !slf.wait(cp, timeout) {
+			slf.drop(cp)
+		}
+	case WriteQueuePolicyDropOldest:
+		for {
+			if (len(slf.packets) == 0 || !slf.overflow(size)) && slf.offer(cp) {
+				break
+			}
+			select {
+			case old := <-slf.packets:
+				slf.pendingBytes.Add(-int64(len(old.packet)))
+				slf.drop(old)
+			default:
+			}
+		}
+	case WriteQueuePolicyDropNewest:
+		slf.drop(cp)
+	case WriteQueuePolicyClose:
+		slf.drop(cp)
+		return true
+	}
+	return false
+}
+
+// wait 阻塞等待直到数据包放入写入队列、连接关闭或超时
+func (slf *Conn) wait(cp *connPacket, timeout time.Duration) bool {
+	var deadline, retry <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	if q := slf.server.writeQueue; q != nil && q.bytes > 0 {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		retry = ticker.C
+	}
+	size := int64(len(cp.packet))
+	for {
+		var packets chan *connPacket
+		if !slf.overflow(int(size)) {
+			packets = slf.packets
+		}
+		slf.pendingBytes.Add(size)
+		select {
+		case packets <- cp:
+			return true
+		case <-retry:
+			slf.pendingBytes.Add(-size)
+		case <-slf.writeAbort:
+			slf.pendingBytes.Add(-size)
+			return false
+		case <-deadline:
+			slf.pendingBytes.Add(-size)
+			return false
+		}
+	}
+}
+
+// drop 丢弃未能写入的数据包，并通过回调函数通知 ErrConnectionWriteQueueFull 错误
+func (slf *Conn) drop(cp *connPacket) {
+	slf.server.metrics.dropped()
+	callback := cp.callback
+	slf.packetPool.Release(cp)
+	if callback != nil {
+		callback(ErrConnectionWriteQueueFull)
+	}
+}
+
+// slow 触发连接写入缓慢事件，同一连接每秒最多触发一次
+func (slf *Conn) slow(policy WriteQueuePolicy) {
+	now := time.Now().UnixNano()
+	last := slf.slowAt.Load()
+	if last != 0 && now-last < int64(time.Second) {
+		return
+	}
+	if !slf.slowAt.CompareAndSwap(last, now) {
+		return
+	}
+	slf.server.OnConnectionSlowEvent(slf, policy, slf.GetPendingPackets(), slf.GetPendingBytes())
+}
@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestWithConnectionWriteQueue(t *testing.T) {
+	var slow = make(chan int64, 1)
+	var done = make(chan struct{})
+	srv := server.New(server.NetworkNone, server.WithConnectionWriteQueue(2, 0, server.WriteQueuePolicyDropNewest, 0))
+	srv.RegConnectionSlowEvent(func(srv *server.Server, conn *server.Conn, policy server.WriteQueuePolicy, pendingPackets int, pendingBytes int64) {
+		if policy != server.WriteQueuePolicyDropNewest || pendingPackets != 2 {
+			t.Error(policy, pendingPackets)
+		}
+		slow <- pendingBytes
+	})
+	srv.RegMessageReadyEvent(func(srv *server.Server) {
+		go func() {
+			defer close(done)
+			var conn = server.NewEmptyConn(srv)
+			var entered, release = make(chan struct{}), make(chan struct{})
+			conn.Write([]byte("blocked"), func(err error) {
+				close(entered)
+				<-release
+			})
+			<-entered
+			conn.Write([]byte("a"))
+			conn.Write([]byte("b"))
+			var dropped error
+			conn.Write([]byte("c"), func(err error) {
+				dropped = err
+			})
+			close(release)
+			if !errors.Is(dropped, server.ErrConnectionWriteQueueFull) {
+				t.Error(dropped)
+			}
+		}()
+	})
+	go func() {
+		if err := srv.RunNone(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	select {
+	case pendingBytes := <-slow:
+		if pendingBytes != 2 {
+			t.Fatal(pendingBytes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	<-done
+	srv.Shutdown()
+}
@@ -0,0 +1,308 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"time"
+)
+
+const (
+	SecureVersion = 1 // 安全会话协议版本
+
+	SecureReplayWindowSize = 64 // 启用滑动窗口时允许乱序到达的数据包数量
+
+	secureKeySize    = 32
+	secureHeaderSize = 9  // 1 字节密钥代数 + 8 字节序列号
+	secureMaxSkip    = 16 // 接收方允许一次向前追赶的密钥代数
+)
+
+var (
+	ErrSecureUnsupportedVersion = errors.New("unsupported secure session version")
+	ErrSecureUnsupportedCipher  = errors.New("unsupported secure session cipher")
+	ErrSecureHandshakeInvalid   = errors.New("invalid secure session handshake")
+	ErrSecureFrameInvalid       = errors.New("invalid secure session frame")
+	ErrSecureReplay             = errors.New("secure session frame sequence mismatch, possible replay")
+)
+
+// SecureCipher 安全会话使用的对称加密算法
+type SecureCipher byte
+
+const (
+	SecureCipherAESGCM           SecureCipher = iota + 1 // AES-256-GCM
+	SecureCipherChaCha20Poly1305                         // ChaCha20-Poly1305
+)
+
+// NewSecureKeyPair 生成用于密钥交换的 X25519 密钥对
+func NewSecureKeyPair() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// EncodeSecureHandshake 生成安全会话的握手数据包，格式为 1 字节版本 + 1 字节加密算法 + 公钥
+func EncodeSecureHandshake(cipher SecureCipher, public *ecdh.PublicKey) []byte {
+	return append([]byte{SecureVersion, byte(cipher)}, public.Bytes()...)
+}
+
+// DecodeSecureHandshake 解析安全会话的握手数据包
+func DecodeSecureHandshake(packet []byte) (SecureCipher, *ecdh.PublicKey, error) {
+	if len(packet) < 2 {
+		return 0, nil, ErrSecureHandshakeInvalid
+	}
+	if packet[0] != SecureVersion {
+		return 0, nil, ErrSecureUnsupportedVersion
+	}
+	cipher := SecureCipher(packet[1])
+	if cipher != SecureCipherAESGCM && cipher != SecureCipherChaCha20Poly1305 {
+		return 0, nil, ErrSecureUnsupportedCipher
+	}
+	public, err := ecdh.X25519().NewPublicKey(packet[2:])
+	if err != nil {
+		return 0, nil, ErrSecureHandshakeInvalid
+	}
+	return cipher, public, nil
+}
+
+// NewSecureSession 通过本地私钥及对端公钥协商出安全会话
+//   - server 表示本地是否为服务端，服务端与客户端将使用不同方向的密钥
+//   - 发送的数据包数量达到 rotatePackets 或距上次轮换超过 rotateInterval 时将轮换发送方向的密钥，为 0 时表示不限制
+//   - 未设置 psk 时密钥交换未经认证，无法抵御中间人攻击；设置 psk 后其将参与密钥派生，未持有相同 psk 的一方无法解密或伪造数据包
+func NewSecureSession(cipher SecureCipher, local *ecdh.PrivateKey, remote *ecdh.PublicKey, server bool, rotatePackets uint64, rotateInterval time.Duration, psk ...[]byte) (*SecureSession, error) {
+	secret, err := local.ECDH(remote)
+	if err != nil {
+		return nil, err
+	}
+	if len(psk) > 0 && len(psk[0]) > 0 {
+		secret = append(secret, psk[0]...)
+	}
+	var salt []byte
+	if server {
+		salt = append(local.PublicKey().Bytes(), remote.Bytes()...)
+	} else {
+		salt = append(remote.Bytes(), local.PublicKey().Bytes()...)
+	}
+	c2s, err := deriveSecureKey(secret, salt, "minotaur secure c2s")
+	if err != nil {
+		return nil, err
+	}
+	s2c, err := deriveSecureKey(secret, salt, "minotaur secure s2c")
+	if err != nil {
+		return nil, err
+	}
+	session := &SecureSession{cipher: cipher, rotatePackets: rotatePackets, rotateInterval: rotateInterval}
+	if server {
+		err = session.init(s2c, c2s)
+	} else {
+		err = session.init(c2s, s2c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// SecureSession 基于 AEAD 的安全会话
+//   - 每个数据包将使用递增的序列号作为随机数，默认情况下接收方仅接受序列号连续的数据包，以防止重放、乱序及丢包
+//   - 通过 EnableReplayWindow 启用滑动窗口后，接收方将接受窗口内乱序到达的数据包，适用于数据报传输
+//   - 数据包中携带发送方的密钥代数，接收方仅在数据包通过认证后才会同步轮换接收方向的密钥
+//   - Seal 及 Open 分别应在同一协程中调用，二者之间可并发调用
+type SecureSession struct {
+	cipher         SecureCipher  // 加密算法
+	rotatePackets  uint64        // 轮换密钥的数据包数量
+	rotateInterval time.Duration // 轮换密钥的时间间隔
+	windowed       bool          // 是否启用滑动窗口
+	send           secureStream  // 发送方向
+	recv           secureStream  // 接收方向
+}
+
+// secureStream 安全会话中单个方向的状态
+type secureStream struct {
+	key       []byte      // 当前密钥
+	aead      cipher.AEAD // 当前加密器
+	prev      cipher.AEAD // 上一代密钥的加密器，用于解密乱序到达的数据包
+	epoch     byte        // 当前密钥代数
+	seq       uint64      // 下一个序列号
+	window    uint64      // 已接收的序列号窗口，第 i 位表示 seq-1-i 是否已接收
+	count     uint64      // 当前密钥已处理的数据包数量
+	rotatedAt time.Time   // 最近一次轮换密钥的时间
+}
+
+// init 初始化两个方向的密钥
+func (slf *SecureSession) init(send, recv []byte) (err error) {
+	if err = slf.send.reset(slf.cipher, send); err != nil {
+		return err
+	}
+	return slf.recv.reset(slf.cipher, recv)
+}
+
+// EnableReplayWindow 启用大小为 SecureReplayWindowSize 的滑动窗口
+//   - 启用后接收方将接受窗口内乱序到达或前序数据包丢失的数据包，重复或早于窗口的数据包依旧会被拒绝
+//   - 适用于 UDP 等可能出现丢包及乱序的数据报传输，应在调用 Open 前启用
+func (slf *SecureSession) EnableReplayWindow() {
+	slf.windowed = true
+}
+
+// Seal 加密数据包，返回的数据包格式为 1 字节密钥代数 + 8 字节序列号 + 密文
+func (slf *SecureSession) Seal(packet []byte) ([]byte, error) {
+	if (slf.rotatePackets > 0 && slf.send.count >= slf.rotatePackets) ||
+		(slf.rotateInterval > 0 && time.Since(slf.send.rotatedAt) >= slf.rotateInterval) {
+		key, aead, err := rotateSecureKey(slf.cipher, slf.send.key)
+		if err != nil {
+			return nil, err
+		}
+		slf.send.commit(key, aead, nil, slf.send.epoch+1)
+	}
+	frame := make([]byte, secureHeaderSize, secureHeaderSize+len(packet)+slf.send.aead.Overhead())
+	frame[0] = slf.send.epoch
+	binary.BigEndian.PutUint64(frame[1:secureHeaderSize], slf.send.seq)
+	frame = slf.send.aead.Seal(frame, secureNonce(slf.send.aead, slf.send.seq), packet, frame[:secureHeaderSize])
+	slf.send.seq++
+	slf.send.count++
+	return frame, nil
+}
+
+// Open 解密通过 Seal 加密的数据包
+//   - 数据包未通过认证时将返回错误，且不会改变会话的任何状态
+func (slf *SecureSession) Open(frame []byte) ([]byte, error) {
+	if len(frame) < secureHeaderSize+slf.recv.aead.Overhead() {
+		return nil, ErrSecureFrameInvalid
+	}
+	seq := binary.BigEndian.Uint64(frame[1:secureHeaderSize])
+	if !slf.recv.acceptable(seq, slf.windowed) {
+		return nil, ErrSecureReplay
+	}
+
+	// 发送方轮换密钥后，接收方派生出候选密钥，仅在数据包通过认证后才会采用
+	var key []byte
+	var aead, prev cipher.AEAD
+	var err error
+	switch skip := frame[0] - slf.recv.epoch; {
+	case skip == 0:
+		aead = slf.recv.aead
+	case skip == 0xff && slf.recv.prev != nil:
+		aead = slf.recv.prev
+	case skip <= secureMaxSkip:
+		if key, aead, prev, err = slf.recv.candidate(slf.cipher, skip); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrSecureFrameInvalid
+	}
+	packet, err := aead.Open(nil, secureNonce(aead, seq), frame[secureHeaderSize:], frame[:secureHeaderSize])
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		slf.recv.commit(key, aead, prev, frame[0])
+	}
+	slf.recv.accept(seq)
+	slf.recv.count++
+	return packet, nil
+}
+
+// acceptable 检查序列号是否可以被接收
+func (slf *secureStream) acceptable(seq uint64, windowed bool) bool {
+	switch {
+	case seq == slf.seq:
+		return true
+	case !windowed:
+		return false
+	case seq > slf.seq:
+		return true
+	}
+	offset := slf.seq - 1 - seq
+	return offset < SecureReplayWindowSize && slf.window&(1<<offset) == 0
+}
+
+// accept 记录已接收的序列号
+func (slf *secureStream) accept(seq uint64) {
+	if seq < slf.seq {
+		slf.window |= 1 << (slf.seq - 1 - seq)
+		return
+	}
+	if shift := seq - slf.seq + 1; shift < SecureReplayWindowSize {
+		slf.window = slf.window<<shift | 1
+	} else {
+		slf.window = 1
+	}
+	slf.seq = seq + 1
+}
+
+// candidate 由当前密钥向前派生 skip 代作为候选密钥，prev 为候选密钥上一代的加密器
+func (slf *secureStream) candidate(algorithm SecureCipher, skip byte) (key []byte, aead, prev cipher.AEAD, err error) {
+	key, aead = slf.key, slf.aead
+	for i := byte(0); i < skip; i++ {
+		prev = aead
+		if key, aead, err = rotateSecureKey(algorithm, key); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return key, aead, prev, nil
+}
+
+// commit 采用新的密钥
+func (slf *secureStream) commit(key []byte, aead, prev cipher.AEAD, epoch byte) {
+	slf.key, slf.aead, slf.prev, slf.epoch = key, aead, prev, epoch
+	slf.count, slf.rotatedAt = 0, time.Now()
+}
+
+// reset 使用初始密钥重置加密器
+func (slf *secureStream) reset(algorithm SecureCipher, key []byte) (err error) {
+	aead, err := newSecureAEAD(algorithm, key)
+	if err != nil {
+		return err
+	}
+	slf.key, slf.aead, slf.count, slf.rotatedAt = key, aead, 0, time.Now()
+	return nil
+}
+
+// newSecureAEAD 通过密钥创建加密器
+func newSecureAEAD(algorithm SecureCipher, key []byte) (aead cipher.AEAD, err error) {
+	switch algorithm {
+	case SecureCipherAESGCM:
+		var block cipher.Block
+		if block, err = aes.NewCipher(key); err == nil {
+			aead, err = cipher.NewGCM(block)
+		}
+	case SecureCipherChaCha20Poly1305:
+		aead, err = chacha20poly1305.New(key)
+	default:
+		err = ErrSecureUnsupportedCipher
+	}
+	return aead, err
+}
+
+// rotateSecureKey 由密钥派生出下一代密钥
+func rotateSecureKey(algorithm SecureCipher, key []byte) ([]byte, cipher.AEAD, error) {
+	key, err := deriveSecureKey(key, nil, "minotaur secure rotate")
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newSecureAEAD(algorithm, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, aead, nil
+}
+
+// secureNonce 获取序列号对应的随机数
+func secureNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+// deriveSecureKey 通过 HKDF-SHA256 派生密钥
+func deriveSecureKey(secret, salt []byte, info string) ([]byte, error) {
+	key := make([]byte, secureKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
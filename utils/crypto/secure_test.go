@@ -0,0 +1,180 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/crypto"
+	"testing"
+	"time"
+)
+
+// newSecurePair 通过握手数据包协商出服务端及客户端的安全会话
+func newSecurePair(t *testing.T, cipher crypto.SecureCipher, rotatePackets uint64, rotateInterval time.Duration) (server, client *crypto.SecureSession) {
+	serverKey, err := crypto.NewSecureKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey, err := crypto.NewSecureKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, serverPub, err := crypto.DecodeSecureHandshake(crypto.EncodeSecureHandshake(cipher, serverKey.PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, clientPub, err := crypto.DecodeSecureHandshake(crypto.EncodeSecureHandshake(cipher, clientKey.PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server, err = crypto.NewSecureSession(cipher, serverKey, clientPub, true, rotatePackets, rotateInterval); err != nil {
+		t.Fatal(err)
+	}
+	if client, err = crypto.NewSecureSession(cipher, clientKey, serverPub, false, rotatePackets, rotateInterval); err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func seal(t *testing.T, session *crypto.SecureSession, packet string) []byte {
+	frame, err := session.Seal([]byte(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+func open(t *testing.T, session *crypto.SecureSession, frame []byte, expect string) {
+	packet, err := session.Open(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(packet) != expect {
+		t.Fatal(string(packet), expect)
+	}
+}
+
+func TestSecureSession_Cipher(t *testing.T) {
+	for _, cipher := range []crypto.SecureCipher{crypto.SecureCipherAESGCM, crypto.SecureCipherChaCha20Poly1305} {
+		server, client := newSecurePair(t, cipher, 0, 0)
+		for _, packet := range []string{"hello", "", "minotaur"} {
+			frame := seal(t, client, packet)
+			if len(packet) > 0 && bytes.Contains(frame, []byte(packet)) {
+				t.Fatal("plaintext leaked", cipher)
+			}
+			open(t, server, frame, packet)
+			open(t, client, seal(t, server, packet), packet)
+		}
+	}
+	if _, _, err := crypto.DecodeSecureHandshake([]byte{crypto.SecureVersion, 0xff}); !errors.Is(err, crypto.ErrSecureUnsupportedCipher) {
+		t.Fatal(err)
+	}
+}
+
+func TestSecureSession_Tamper(t *testing.T) {
+	server, client := newSecurePair(t, crypto.SecureCipherAESGCM, 0, 0)
+	frame := seal(t, client, "hello")
+	for i := range frame {
+		tampered := bytes.Clone(frame)
+		tampered[i] ^= 1
+		if _, err := server.Open(tampered); err == nil {
+			t.Fatal("tampered frame opened", i)
+		}
+	}
+	// 未通过认证的数据包不会影响会话状态
+	open(t, server, frame, "hello")
+}
+
+func TestSecureSession_Replay(t *testing.T) {
+	server, client := newSecurePair(t, crypto.SecureCipherAESGCM, 0, 0)
+	first, second := seal(t, client, "a"), seal(t, client, "b")
+	if _, err := server.Open(second); !errors.Is(err, crypto.ErrSecureReplay) {
+		t.Fatal(err)
+	}
+	open(t, server, first, "a")
+	if _, err := server.Open(first); !errors.Is(err, crypto.ErrSecureReplay) {
+		t.Fatal(err)
+	}
+	open(t, server, second, "b")
+}
+
+func TestSecureSession_ReplayWindow(t *testing.T) {
+	server, client := newSecurePair(t, crypto.SecureCipherChaCha20Poly1305, 0, 0)
+	server.EnableReplayWindow()
+	var frames [][]byte
+	for i := 0; i < crypto.SecureReplayWindowSize+2; i++ {
+		frames = append(frames, seal(t, client, string(rune('a'+i%26))))
+	}
+	// 丢失及乱序到达的数据包均可被接收，重复的数据包将被拒绝
+	open(t, server, frames[2], "c")
+	open(t, server, frames[0], "a")
+	if _, err := server.Open(frames[0]); !errors.Is(err, crypto.ErrSecureReplay) {
+		t.Fatal(err)
+	}
+	last := len(frames) - 1
+	open(t, server, frames[last], string(rune('a'+last%26)))
+	// 早于窗口的数据包将被拒绝
+	if _, err := server.Open(frames[1]); !errors.Is(err, crypto.ErrSecureReplay) {
+		t.Fatal(err)
+	}
+	open(t, server, frames[last-1], string(rune('a'+(last-1)%26)))
+}
+
+func TestSecureSession_RotatePackets(t *testing.T) {
+	server, client := newSecurePair(t, crypto.SecureCipherAESGCM, 2, 0)
+	for i := 0; i < 7; i++ {
+		open(t, server, seal(t, client, "packet"), "packet")
+	}
+	frame := seal(t, client, "rotated")
+	if frame[0] == 0 {
+		t.Fatal("key not rotated")
+	}
+	// 伪造的轮换数据包不会导致接收方轮换密钥
+	forged := bytes.Clone(frame)
+	forged[0]++
+	if _, err := server.Open(forged); err == nil {
+		t.Fatal("forged frame opened")
+	}
+	open(t, server, frame, "rotated")
+}
+
+func TestSecureSession_RotateInterval(t *testing.T) {
+	server, client := newSecurePair(t, crypto.SecureCipherChaCha20Poly1305, 0, 50*time.Millisecond)
+	server.EnableReplayWindow()
+	before := seal(t, client, "before")
+	time.Sleep(60 * time.Millisecond)
+	rotated := seal(t, client, "rotated")
+	after := seal(t, client, "after")
+	if rotated[0] != before[0]+1 || after[0] != rotated[0] {
+		t.Fatal(before[0], rotated[0], after[0])
+	}
+	// 携带轮换标记的数据包丢失后，接收方依旧可以追赶密钥，且可以解密上一代密钥加密的乱序数据包
+	open(t, server, after, "after")
+	open(t, server, before, "before")
+	open(t, server, rotated, "rotated")
+}
+
+func TestSecureSession_PSK(t *testing.T) {
+	var psk = []byte("minotaur")
+	for _, c := range []struct {
+		client []byte
+		opened bool
+	}{{psk, true}, {[]byte("attacker"), false}, {nil, false}} {
+		serverKey, _ := crypto.NewSecureKeyPair()
+		clientKey, _ := crypto.NewSecureKeyPair()
+		server, err := crypto.NewSecureSession(crypto.SecureCipherAESGCM, serverKey, clientKey.PublicKey(), true, 0, 0, psk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := crypto.NewSecureSession(crypto.SecureCipherAESGCM, clientKey, serverKey.PublicKey(), false, 0, 0, c.client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 未持有相同预共享密钥的一方无法通过认证
+		if _, err = server.Open(seal(t, client, "hello")); (err == nil) != c.opened {
+			t.Fatal(string(c.client), err)
+		}
+		if _, err = client.Open(seal(t, server, "hello")); (err == nil) != c.opened {
+			t.Fatal(string(c.client), err)
+		}
+	}
+}